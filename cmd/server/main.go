@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/ricardo/mcp-ocr-server/internal/config"
 	"github.com/ricardo/mcp-ocr-server/internal/server"
@@ -78,7 +80,15 @@ func main() {
 		logger.Error("Server error", zap.Error(err))
 	}
 
-	// 优雅关闭
+	// 优雅关闭: 先停止传输层 (等待进行中的请求完成)，再释放资源
+	shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Error shutting down server", zap.Error(err))
+	}
+
 	if err := srv.Close(); err != nil {
 		logger.Error("Error closing server", zap.Error(err))
 	}
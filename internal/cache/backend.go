@@ -0,0 +1,35 @@
+package cache
+
+// 缓存后端类型 (performance.cache_backend)
+const (
+	BackendMemory = "memory" // 内存缓存，重启后清空
+	BackendDisk   = "disk"   // 磁盘缓存，重启后保留
+)
+
+// Backend 缓存存储后端
+type Backend interface {
+	// Get 获取缓存值，不存在或已过期时返回 false
+	Get(key string) (interface{}, bool)
+
+	// Set 设置缓存值
+	Set(key string, value interface{})
+
+	// Delete 删除缓存值
+	Delete(key string)
+
+	// DeletePrefix 删除以 prefix 开头的全部键，返回删除的条目数
+	// 用于按图像删除结果 (见 Fingerprint.Key)
+	DeletePrefix(prefix string) int
+
+	// Clear 清空缓存
+	Clear()
+
+	// Size 获取缓存条目数
+	Size() int
+
+	// Stats 获取缓存统计信息
+	Stats() map[string]interface{}
+
+	// Close 停止后台清理 (内存缓存同时清空，磁盘缓存保留已写入的条目)
+	Close() error
+}
\ No newline at end of file
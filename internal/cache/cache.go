@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,31 +12,54 @@ import (
 	"go.uber.org/zap"
 )
 
+// defaultValueSize 无法确定大小的缓存值的估计大小 (字节)
+const defaultValueSize = 1024
+
+// Sizer 可报告自身大小的缓存值，用于按总大小淘汰
+type Sizer interface {
+	Size() int64
+}
+
 // CacheEntry 缓存条目
 type CacheEntry struct {
+	Key        string
 	Value      interface{}
+	Size       int64 // 估计占用的字节数 (键 + 值)
 	ExpireTime time.Time
 }
 
-// Cache OCR 结果缓存
+// Cache OCR 结果缓存 (内存 LRU)
+// 超出条目数或总大小上限时淘汰最久未使用的条目，读取和写入均为 O(1)
 type Cache struct {
-	store   map[string]*CacheEntry
-	maxSize int
-	ttl     time.Duration
-	mu      sync.RWMutex
-	enabled bool
+	entries   map[string]*list.Element
+	order     *list.List // 按最近访问排序，最久未使用的在前
+	maxSize   int
+	maxBytes  int64
+	ttl       time.Duration
+	bytes     int64
+	hits      int64
+	misses    int64
+	evictions int64
+	mu        sync.Mutex
+	enabled   bool
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 // NewCache 创建缓存实例
-func NewCache(maxSize int, ttl time.Duration, enabled bool) *Cache {
+// maxSize 为最多保留的条目数，maxBytes 为总大小上限 (均为 0 表示不限制)，ttl 为保留时间 (0 表示不过期)
+func NewCache(maxSize int, maxBytes int64, ttl time.Duration, enabled bool) *Cache {
 	cache := &Cache{
-		store:   make(map[string]*CacheEntry),
-		maxSize: maxSize,
-		ttl:     ttl,
-		enabled: enabled,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		maxSize:  maxSize,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		enabled:  enabled,
+		stop:     make(chan struct{}),
 	}
 
-	if enabled {
+	if enabled && ttl > 0 {
 		// 启动清理协程
 		go cache.cleanupRoutine()
 	}
@@ -48,19 +73,26 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 		return nil, false
 	}
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	entry, exists := c.store[key]
+	elem, exists := c.entries[key]
 	if !exists {
+		c.misses++
 		return nil, false
 	}
 
 	// 检查是否过期
-	if time.Now().After(entry.ExpireTime) {
+	entry := elem.Value.(*CacheEntry)
+	if c.expired(entry, time.Now()) {
+		c.removeLocked(elem)
+		c.misses++
 		return nil, false
 	}
 
+	c.order.MoveToBack(elem)
+	c.hits++
+
 	logger.Debug("Cache hit", zap.String("key", key))
 	return entry.Value, true
 }
@@ -74,14 +106,35 @@ func (c *Cache) Set(key string, value interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 检查缓存是否已满
-	if len(c.store) >= c.maxSize {
-		c.evictOldest()
+	if elem, exists := c.entries[key]; exists {
+		c.removeLocked(elem)
+	}
+
+	entry := &CacheEntry{
+		Key:   key,
+		Value: value,
+		Size:  int64(len(key)) + sizeOf(value),
+	}
+
+	// 单个条目超过总大小上限时不缓存 (否则会淘汰其他所有条目并一直占用超出上限的内存)
+	if c.maxBytes > 0 && entry.Size > c.maxBytes {
+		logger.Debug("Cache entry too large, not cached", zap.String("key", key), zap.Int64("size", entry.Size))
+		return
 	}
 
-	c.store[key] = &CacheEntry{
-		Value:      value,
-		ExpireTime: time.Now().Add(c.ttl),
+	if c.ttl > 0 {
+		entry.ExpireTime = time.Now().Add(c.ttl)
+	}
+
+	c.entries[key] = c.order.PushBack(entry)
+	c.bytes += entry.Size
+
+	// 超出限制时淘汰最久未使用的条目
+	for c.order.Len() > 0 && ((c.maxSize > 0 && c.order.Len() > c.maxSize) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		evicted := c.order.Front()
+		logger.Debug("Cache evicted least recently used", zap.String("key", evicted.Value.(*CacheEntry).Key))
+		c.removeLocked(evicted)
+		c.evictions++
 	}
 
 	logger.Debug("Cache set", zap.String("key", key))
@@ -96,10 +149,35 @@ func (c *Cache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.store, key)
+	if elem, exists := c.entries[key]; exists {
+		c.removeLocked(elem)
+	}
 	logger.Debug("Cache deleted", zap.String("key", key))
 }
 
+// DeletePrefix 删除以 prefix 开头的全部键，返回删除的条目数
+func (c *Cache) DeletePrefix(prefix string) int {
+	if !c.enabled {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if strings.HasPrefix(elem.Value.(*CacheEntry).Key, prefix) {
+			c.removeLocked(elem)
+			removed++
+		}
+		elem = next
+	}
+
+	logger.Debug("Cache deleted by prefix", zap.String("prefix", prefix), zap.Int("count", removed))
+	return removed
+}
+
 // Clear 清空缓存
 func (c *Cache) Clear() {
 	if !c.enabled {
@@ -109,46 +187,58 @@ func (c *Cache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.store = make(map[string]*CacheEntry)
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.bytes = 0
 	logger.Info("Cache cleared")
 }
 
 // Size 获取缓存大小
 func (c *Cache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.store)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
 }
 
 // Stats 获取缓存统计信息
 func (c *Cache) Stats() map[string]interface{} {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return map[string]interface{}{
-		"size":     len(c.store),
-		"max_size": c.maxSize,
+		"size":        c.order.Len(),
+		"max_size":    c.maxSize,
+		"bytes":       c.bytes,
+		"max_bytes":   c.maxBytes,
 		"ttl_seconds": c.ttl.Seconds(),
-		"enabled": c.enabled,
+		"enabled":     c.enabled,
+		"backend":     BackendMemory,
+		"hits":        c.hits,
+		"misses":      c.misses,
+		"evictions":   c.evictions,
+		"hit_rate":    hitRate(c.hits, c.misses),
 	}
 }
 
-// evictOldest 淘汰最旧的条目
-func (c *Cache) evictOldest() {
-	var oldestKey string
-	var oldestTime time.Time
+// Close 停止清理协程并清空缓存
+func (c *Cache) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+	c.Clear()
+	return nil
+}
 
-	for key, entry := range c.store {
-		if oldestKey == "" || entry.ExpireTime.Before(oldestTime) {
-			oldestKey = key
-			oldestTime = entry.ExpireTime
-		}
-	}
+// expired 条目是否已过期
+func (c *Cache) expired(entry *CacheEntry, now time.Time) bool {
+	return !entry.ExpireTime.IsZero() && now.After(entry.ExpireTime)
+}
 
-	if oldestKey != "" {
-		delete(c.store, oldestKey)
-		logger.Debug("Cache evicted oldest", zap.String("key", oldestKey))
-	}
+// removeLocked 删除条目 (调用方需持有锁)
+func (c *Cache) removeLocked(elem *list.Element) {
+	entry := c.order.Remove(elem).(*CacheEntry)
+	delete(c.entries, entry.Key)
+	c.bytes -= entry.Size
 }
 
 // cleanupRoutine 定期清理过期条目
@@ -156,8 +246,13 @@ func (c *Cache) cleanupRoutine() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stop:
+			return
+		}
 	}
 }
 
@@ -167,21 +262,42 @@ func (c *Cache) cleanup() {
 	defer c.mu.Unlock()
 
 	now := time.Now()
-	expiredKeys := make([]string, 0)
+	expired := 0
 
-	for key, entry := range c.store {
-		if now.After(entry.ExpireTime) {
-			expiredKeys = append(expiredKeys, key)
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if c.expired(elem.Value.(*CacheEntry), now) {
+			c.removeLocked(elem)
+			expired++
 		}
+		elem = next
 	}
 
-	for _, key := range expiredKeys {
-		delete(c.store, key)
+	if expired > 0 {
+		logger.Debug("Cache cleanup", zap.Int("expired_count", expired))
 	}
+}
+
+// sizeOf 估计缓存值占用的字节数
+func sizeOf(value interface{}) int64 {
+	switch v := value.(type) {
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	default:
+		return defaultValueSize
+	}
+}
 
-	if len(expiredKeys) > 0 {
-		logger.Debug("Cache cleanup", zap.Int("expired_count", len(expiredKeys)))
+// hitRate 命中率 (0-1，没有请求时为 0)
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
 	}
+	return float64(hits) / float64(hits+misses)
 }
 
 // GenerateKey 生成缓存键 (基于图像数据的 SHA256 哈希)
@@ -6,7 +6,7 @@ import (
 )
 
 func TestCache_GetSet(t *testing.T) {
-	cache := NewCache(10, time.Second*5, true)
+	cache := NewCache(10, 0, time.Second*5, true)
 
 	// 测试设置和获取
 	cache.Set("key1", "value1")
@@ -22,7 +22,7 @@ func TestCache_GetSet(t *testing.T) {
 }
 
 func TestCache_Expiration(t *testing.T) {
-	cache := NewCache(10, time.Millisecond*100, true)
+	cache := NewCache(10, 0, time.Millisecond*100, true)
 
 	cache.Set("key1", "value1")
 
@@ -36,7 +36,7 @@ func TestCache_Expiration(t *testing.T) {
 }
 
 func TestCache_MaxSize(t *testing.T) {
-	cache := NewCache(3, time.Hour, true)
+	cache := NewCache(3, 0, time.Hour, true)
 
 	cache.Set("key1", "value1")
 	cache.Set("key2", "value2")
@@ -49,7 +49,7 @@ func TestCache_MaxSize(t *testing.T) {
 }
 
 func TestCache_Clear(t *testing.T) {
-	cache := NewCache(10, time.Hour, true)
+	cache := NewCache(10, 0, time.Hour, true)
 
 	cache.Set("key1", "value1")
 	cache.Set("key2", "value2")
@@ -77,7 +77,7 @@ func TestGenerateKey(t *testing.T) {
 }
 
 func TestCache_Disabled(t *testing.T) {
-	cache := NewCache(10, time.Hour, false)
+	cache := NewCache(10, 0, time.Hour, false)
 
 	cache.Set("key1", "value1")
 
@@ -85,4 +85,133 @@ func TestCache_Disabled(t *testing.T) {
 	if found {
 		t.Error("Expected cache to be disabled")
 	}
+}
+
+type sizedValue int64
+
+func (v sizedValue) Size() int64 {
+	return int64(v)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(2, 0, time.Hour, true)
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Get("key1") // key2 成为最久未使用的条目
+	cache.Set("key3", "value3")
+
+	if _, found := cache.Get("key2"); found {
+		t.Error("Expected key2 to be evicted")
+	}
+	if _, found := cache.Get("key1"); !found {
+		t.Error("Expected key1 to be kept")
+	}
+	if _, found := cache.Get("key3"); !found {
+		t.Error("Expected key3 to be kept")
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	cache := NewCache(0, 250, time.Hour, true)
+
+	cache.Set("a", sizedValue(99)) // 键 1 字节 + 值 99 字节
+	cache.Set("b", sizedValue(99))
+	cache.Set("c", sizedValue(99)) // 超出 250 字节，淘汰 a
+
+	if cache.Size() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Size())
+	}
+	if _, found := cache.Get("a"); found {
+		t.Error("Expected a to be evicted")
+	}
+
+	stats := cache.Stats()
+	if stats["bytes"] != int64(200) {
+		t.Errorf("Expected 200 bytes, got %v", stats["bytes"])
+	}
+
+	// 单个条目超过上限时不缓存，也不淘汰其他条目
+	cache.Set("d", sizedValue(1000))
+	if _, found := cache.Get("d"); found {
+		t.Error("Expected oversized entry to be rejected")
+	}
+	if cache.Size() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Size())
+	}
+
+	// 覆盖为超出上限的值时删除旧条目
+	cache.Set("b", sizedValue(1000))
+	if _, found := cache.Get("b"); found {
+		t.Error("Expected stale b to be removed")
+	}
+	if cache.Size() != 1 {
+		t.Errorf("Expected 1 entry, got %d", cache.Size())
+	}
+}
+
+func TestCache_Overwrite(t *testing.T) {
+	cache := NewCache(10, 0, time.Hour, true)
+
+	cache.Set("key1", "a")
+	cache.Set("key1", "abcdef")
+
+	if cache.Size() != 1 {
+		t.Errorf("Expected 1 entry, got %d", cache.Size())
+	}
+	if value, _ := cache.Get("key1"); value != "abcdef" {
+		t.Errorf("Expected abcdef, got %v", value)
+	}
+	if stats := cache.Stats(); stats["bytes"] != int64(len("key1")+len("abcdef")) {
+		t.Errorf("Expected %d bytes, got %v", len("key1")+len("abcdef"), stats["bytes"])
+	}
+}
+
+func TestCache_Stats(t *testing.T) {
+	cache := NewCache(1, 0, time.Hour, true)
+
+	cache.Set("key1", "value1")
+	cache.Get("key1")
+	cache.Get("missing")
+	cache.Set("key2", "value2") // 淘汰 key1
+
+	stats := cache.Stats()
+	if stats["hits"] != int64(1) || stats["misses"] != int64(1) || stats["evictions"] != int64(1) {
+		t.Errorf("Unexpected counters: hits=%v misses=%v evictions=%v", stats["hits"], stats["misses"], stats["evictions"])
+	}
+	if stats["hit_rate"] != 0.5 {
+		t.Errorf("Expected hit rate 0.5, got %v", stats["hit_rate"])
+	}
+}
+
+func TestCache_NoTTL(t *testing.T) {
+	cache := NewCache(10, 0, 0, true)
+
+	cache.Set("key1", "value1")
+	time.Sleep(time.Millisecond * 10)
+
+	if _, found := cache.Get("key1"); !found {
+		t.Error("Expected entry without TTL not to expire")
+	}
+}
+
+func TestCache_DeletePrefix(t *testing.T) {
+	cache := NewCache(10, 0, time.Hour, true)
+
+	cache.Set("img1-a", "value1")
+	cache.Set("img1-b", "value2")
+	cache.Set("img2-a", "value3")
+
+	if removed := cache.DeletePrefix("img1-"); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	if _, found := cache.Get("img1-a"); found {
+		t.Error("Expected img1-a to be deleted")
+	}
+	if _, found := cache.Get("img2-a"); !found {
+		t.Error("Expected img2-a to be kept")
+	}
+	if stats := cache.Stats(); stats["bytes"].(int64) != int64(len("img2-a")+len("value3")) {
+		t.Errorf("Expected bytes to account for remaining entry, got %v", stats["bytes"])
+	}
 }
\ No newline at end of file
@@ -0,0 +1,468 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// diskFileExt 缓存文件扩展名
+const diskFileExt = ".cache"
+
+// DiskCache 磁盘缓存 (每个键一个文件，重启后保留)
+// 键只包含小写字母、数字、'-' 和 '_' 时直接作为文件名 (可按前缀删除)，否则使用键的哈希
+// 值使用 gob 编码，基本类型以外的值需要调用方先调用 gob.Register 注册类型
+// 超出条目数或总大小上限时淘汰最久未使用的条目，过期时间按文件写入时间计算
+type DiskCache struct {
+	dir       string
+	maxItems  int
+	maxBytes  int64
+	ttl       time.Duration
+	entries   map[string]*list.Element // 文件名 -> 条目
+	order     *list.List               // 按最近访问排序，最久未使用的在前
+	bytes     int64
+	hits      int64
+	misses    int64
+	evictions int64
+	mu        sync.Mutex
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// diskEntry 磁盘缓存条目索引
+type diskEntry struct {
+	name       string    // 文件名
+	size       int64     // 文件大小 (字节)
+	expireTime time.Time // 过期时间 (零值表示不过期)
+}
+
+// diskRecord 缓存文件内容
+type diskRecord struct {
+	Key   string
+	Value interface{}
+}
+
+// NewDiskCache 创建磁盘缓存并加载目录中已有的条目
+// maxItems 为最多保留的条目数，maxBytes 为总大小上限 (均为 0 表示不限制)，ttl 为保留时间 (0 表示不过期)
+func NewDiskCache(dir string, maxItems int, maxBytes int64, ttl time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	cache := &DiskCache{
+		dir:      dir,
+		maxItems: maxItems,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		stop:     make(chan struct{}),
+	}
+
+	if err := cache.load(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Disk cache loaded",
+		zap.String("dir", dir),
+		zap.Int("entries", cache.order.Len()),
+		zap.Int64("bytes", cache.bytes),
+	)
+
+	// 启动清理协程
+	go cache.cleanupRoutine()
+
+	return cache, nil
+}
+
+// load 扫描缓存目录建立索引，删除过期条目和未完成写入的临时文件
+func (c *DiskCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type loaded struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+
+	entries := make([]loaded, 0, len(files))
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			continue
+		}
+
+		if !strings.HasSuffix(name, diskFileExt) {
+			// 写入过程中退出留下的临时文件
+			if strings.HasPrefix(name, ".tmp-") {
+				os.Remove(filepath.Join(c.dir, name))
+			}
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, loaded{name: name, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	// 重启后没有访问记录，按写入时间近似最近使用顺序
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, entry := range entries {
+		expireTime := c.expireTime(entry.modTime)
+		if !expireTime.IsZero() && now.After(expireTime) {
+			os.Remove(filepath.Join(c.dir, entry.name))
+			continue
+		}
+
+		c.entries[entry.name] = c.order.PushBack(&diskEntry{
+			name:       entry.name,
+			size:       entry.size,
+			expireTime: expireTime,
+		})
+		c.bytes += entry.size
+	}
+
+	c.evictLocked()
+
+	return nil
+}
+
+// Get 获取缓存值
+func (c *DiskCache) Get(key string) (interface{}, bool) {
+	name := diskFileName(key)
+
+	c.mu.Lock()
+	elem, ok := c.entries[name]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+
+	entry := elem.Value.(*diskEntry)
+	if !entry.expireTime.IsZero() && time.Now().After(entry.expireTime) {
+		c.removeLocked(elem)
+		c.misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+
+	c.order.MoveToBack(elem)
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read cache file", zap.String("file", name), zap.Error(err))
+		}
+		c.discard(elem)
+		return nil, false
+	}
+
+	var record diskRecord
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil || record.Key != key {
+		// 文件损坏或无法解码 (如结果类型已变化)
+		logger.Warn("Discarding unreadable cache file", zap.String("file", name), zap.Error(err))
+		c.discard(elem)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.hits++
+	c.mu.Unlock()
+
+	logger.Debug("Cache hit", zap.String("key", key))
+	return record.Value, true
+}
+
+// Set 设置缓存值 (写入失败时只记录日志)
+func (c *DiskCache) Set(key string, value interface{}) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&diskRecord{Key: key, Value: value}); err != nil {
+		logger.Warn("Failed to encode cache value", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	name := diskFileName(key)
+
+	// 单个条目超过总大小上限时不缓存，并删除同一键的旧条目
+	if c.maxBytes > 0 && int64(buf.Len()) > c.maxBytes {
+		c.mu.Lock()
+		if elem, ok := c.entries[name]; ok {
+			c.removeLocked(elem)
+		}
+		c.mu.Unlock()
+
+		logger.Debug("Cache entry too large, not cached", zap.String("key", key), zap.Int("size", buf.Len()))
+		return
+	}
+
+	if err := c.writeFile(name, buf.Bytes()); err != nil {
+		logger.Warn("Failed to write cache file", zap.String("file", name), zap.Error(err))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[name]; ok {
+		c.bytes -= elem.Value.(*diskEntry).size
+		c.order.Remove(elem)
+	}
+
+	c.entries[name] = c.order.PushBack(&diskEntry{
+		name:       name,
+		size:       int64(buf.Len()),
+		expireTime: c.expireTime(time.Now()),
+	})
+	c.bytes += int64(buf.Len())
+
+	c.evictLocked()
+
+	logger.Debug("Cache set", zap.String("key", key))
+}
+
+// writeFile 先写入临时文件再重命名，避免读取到写了一半的文件
+func (c *DiskCache) writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete 删除缓存值
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[diskFileName(key)]; ok {
+		c.removeLocked(elem)
+	}
+	logger.Debug("Cache deleted", zap.String("key", key))
+}
+
+// DeletePrefix 删除键以 prefix 开头的全部条目，返回删除的条目数
+// 文件名为键哈希的条目 (键包含其他字符) 不参与匹配
+func (c *DiskCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if key, ok := diskFileKey(elem.Value.(*diskEntry).name); ok && strings.HasPrefix(key, prefix) {
+			c.removeLocked(elem)
+			removed++
+		}
+		elem = next
+	}
+
+	logger.Debug("Cache deleted by prefix", zap.String("prefix", prefix), zap.Int("count", removed))
+	return removed
+}
+
+// discard 删除无法读取的条目并计为未命中
+// 文件在锁外读取，只在条目仍是读取时查到的 elem 时删除 (期间并发的 Set 可能已写入新条目)
+func (c *DiskCache) discard(elem *list.Element) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := elem.Value.(*diskEntry).name
+	if current, ok := c.entries[name]; ok && current == elem {
+		c.removeLocked(elem)
+	}
+	c.misses++
+}
+
+// Clear 清空缓存 (删除全部缓存文件)
+func (c *DiskCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.order.Front(); elem != nil; elem = c.order.Front() {
+		c.removeLocked(elem)
+	}
+	logger.Info("Cache cleared")
+}
+
+// Size 获取缓存条目数
+func (c *DiskCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats 获取缓存统计信息
+func (c *DiskCache) Stats() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return map[string]interface{}{
+		"size":        c.order.Len(),
+		"max_size":    c.maxItems,
+		"bytes":       c.bytes,
+		"max_bytes":   c.maxBytes,
+		"ttl_seconds": c.ttl.Seconds(),
+		"enabled":     true,
+		"backend":     BackendDisk,
+		"dir":         c.dir,
+		"hits":        c.hits,
+		"misses":      c.misses,
+		"evictions":   c.evictions,
+		"hit_rate":    hitRate(c.hits, c.misses),
+	}
+}
+
+// Close 停止清理协程 (缓存文件保留，下次启动时加载)
+func (c *DiskCache) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+// expireTime 根据写入时间计算过期时间
+func (c *DiskCache) expireTime(written time.Time) time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return written.Add(c.ttl)
+}
+
+// evictLocked 超出条目数或总大小上限时淘汰最久未使用的条目 (调用方需持有锁)
+func (c *DiskCache) evictLocked() {
+	for c.order.Len() > 0 && ((c.maxItems > 0 && c.order.Len() > c.maxItems) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		elem := c.order.Front()
+		logger.Debug("Cache evicted least recently used", zap.String("file", elem.Value.(*diskEntry).name))
+		c.removeLocked(elem)
+		c.evictions++
+	}
+}
+
+// removeLocked 删除条目及其文件 (调用方需持有锁)
+func (c *DiskCache) removeLocked(elem *list.Element) {
+	entry := c.order.Remove(elem).(*diskEntry)
+	delete(c.entries, entry.name)
+	c.bytes -= entry.size
+
+	if err := os.Remove(filepath.Join(c.dir, entry.name)); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove cache file", zap.String("file", entry.name), zap.Error(err))
+	}
+}
+
+// cleanupRoutine 定期清理过期条目
+func (c *DiskCache) cleanupRoutine() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// cleanup 清理过期条目
+func (c *DiskCache) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	expired := 0
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*diskEntry)
+		if !entry.expireTime.IsZero() && now.After(entry.expireTime) {
+			c.removeLocked(elem)
+			expired++
+		}
+		elem = next
+	}
+
+	if expired > 0 {
+		logger.Debug("Cache cleanup", zap.Int("expired_count", expired))
+	}
+}
+
+// diskHashPrefix 以键哈希命名的文件名前缀，与直接以键命名的文件区分
+const diskHashPrefix = "h_"
+
+// maxDiskKeyLength 直接作为文件名的键的最大长度
+const maxDiskKeyLength = 200
+
+// diskFileName 缓存键对应的文件名
+// 安全的键直接作为文件名，其他键 (包含特殊字符或过长) 使用 SHA256
+func diskFileName(key string) string {
+	if safeDiskKey(key) {
+		return key + diskFileExt
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return diskHashPrefix + hex.EncodeToString(sum[:]) + diskFileExt
+}
+
+// diskFileKey 从文件名还原缓存键，文件名为键哈希时返回 false
+func diskFileKey(name string) (string, bool) {
+	if strings.HasPrefix(name, diskHashPrefix) {
+		return "", false
+	}
+	return strings.TrimSuffix(name, diskFileExt), true
+}
+
+// safeDiskKey 键是否可以直接作为文件名 (非空，只包含小写字母、数字、'-' 和 '_'，且不以哈希前缀开头)
+// 不允许大写字母，避免在大小写不敏感的文件系统上冲突
+func safeDiskKey(key string) bool {
+	if key == "" || len(key) > maxDiskKeyLength || strings.HasPrefix(key, diskHashPrefix) {
+		return false
+	}
+
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
\ No newline at end of file
@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type diskTestResult struct {
+	Text       string
+	Confidence float64
+}
+
+func init() {
+	gob.Register(&diskTestResult{})
+}
+
+func TestDiskCache_GetSet(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", &diskTestResult{Text: "hello", Confidence: 91.5})
+
+	value, found := cache.Get("key1")
+	if !found {
+		t.Fatal("Expected to find key1")
+	}
+
+	result, ok := value.(*diskTestResult)
+	if !ok {
+		t.Fatalf("Expected *diskTestResult, got %T", value)
+	}
+	if result.Text != "hello" || result.Confidence != 91.5 {
+		t.Errorf("Unexpected value: %+v", result)
+	}
+
+	if _, found := cache.Get("missing"); found {
+		t.Error("Expected missing key not to be found")
+	}
+}
+
+func TestDiskCache_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	cache.Set("key1", &diskTestResult{Text: "persisted"})
+	cache.Close()
+
+	reopened, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Size() != 1 {
+		t.Errorf("Expected 1 entry after restart, got %d", reopened.Size())
+	}
+
+	value, found := reopened.Get("key1")
+	if !found {
+		t.Fatal("Expected key1 to survive restart")
+	}
+	if value.(*diskTestResult).Text != "persisted" {
+		t.Errorf("Unexpected value: %+v", value)
+	}
+}
+
+func TestDiskCache_Expiration(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Millisecond*100)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	time.Sleep(time.Millisecond * 150)
+
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected key1 to be expired")
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected expired cache file to be removed, found %d files", len(files))
+	}
+}
+
+func TestDiskCache_ExpiredOnLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	cache.Set("key1", "value1")
+	cache.Close()
+
+	// 将文件写入时间改为两小时前
+	old := time.Now().Add(-2 * time.Hour)
+	path := filepath.Join(dir, diskFileName("key1"))
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	reopened, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Size() != 0 {
+		t.Errorf("Expected expired entry to be dropped on load, got %d entries", reopened.Size())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected expired cache file to be removed on load")
+	}
+}
+
+func TestDiskCache_EvictsBySize(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 0, 250, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	// 两条记录超过上限，淘汰最早写入的条目
+	cache.Set("key1", strings.Repeat("a", 100))
+	cache.Set("key2", strings.Repeat("b", 100))
+
+	if cache.Size() != 1 {
+		t.Errorf("Expected 1 entry, got %d", cache.Size())
+	}
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected key1 to be evicted")
+	}
+	if _, found := cache.Get("key2"); !found {
+		t.Error("Expected key2 to be kept")
+	}
+
+	// 单个条目超过上限时不缓存，也不淘汰其他条目
+	cache.Set("key3", strings.Repeat("c", 1000))
+	if _, found := cache.Get("key3"); found {
+		t.Error("Expected oversized entry to be rejected")
+	}
+	if _, found := cache.Get("key2"); !found {
+		t.Error("Expected key2 to be kept after rejecting oversized entry")
+	}
+
+	// 覆盖为超出上限的值时删除旧条目
+	cache.Set("key2", strings.Repeat("d", 1000))
+	if _, found := cache.Get("key2"); found {
+		t.Error("Expected stale key2 to be removed")
+	}
+	if cache.Size() != 0 {
+		t.Errorf("Expected 0 entries, got %d", cache.Size())
+	}
+}
+
+func TestDiskCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 2, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Get("key1") // key2 成为最久未使用的条目
+	cache.Set("key3", "value3")
+
+	if _, found := cache.Get("key2"); found {
+		t.Error("Expected key2 to be evicted")
+	}
+	if _, found := cache.Get("key1"); !found {
+		t.Error("Expected key1 to be kept")
+	}
+}
+
+func TestDiskCache_DeleteClear(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	cache.Delete("key1")
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected key1 to be deleted")
+	}
+
+	cache.Clear()
+	if cache.Size() != 0 {
+		t.Errorf("Expected cache size 0, got %d", cache.Size())
+	}
+	if stats := cache.Stats(); stats["bytes"] != int64(0) {
+		t.Errorf("Expected 0 bytes, got %v", stats["bytes"])
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected cache directory to be empty, found %d files", len(files))
+	}
+}
+
+func TestDiskCache_CorruptFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	if err := os.WriteFile(filepath.Join(dir, diskFileName("key1")), []byte("garbage"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected corrupt entry to be treated as a miss")
+	}
+	if cache.Size() != 0 {
+		t.Errorf("Expected corrupt entry to be removed, got %d entries", cache.Size())
+	}
+}
+
+func TestDiskCache_DiscardKeepsNewerEntry(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", &diskTestResult{Text: "old"})
+	cache.mu.Lock()
+	stale := cache.entries[diskFileName("key1")]
+	cache.mu.Unlock()
+
+	// 模拟 Get 读取旧文件失败前，并发的 Set 写入了新条目
+	cache.Set("key1", &diskTestResult{Text: "new"})
+	cache.discard(stale)
+
+	value, found := cache.Get("key1")
+	if !found {
+		t.Fatal("Expected newer entry to survive discarding the stale one")
+	}
+	if result := value.(*diskTestResult); result.Text != "new" {
+		t.Errorf("Expected new, got %q", result.Text)
+	}
+}
+
+func TestDiskCache_DeletePrefix(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	cache.Set("img1-a", "value1")
+	cache.Set("img1-b", "value2")
+	cache.Set("img2-a", "value3")
+	cache.Set("img1/unsafe", "value4") // 文件名为键哈希，不参与前缀匹配
+	cache.Close()
+
+	// 重启后仍可按前缀删除
+	reopened, err := NewDiskCache(dir, 10, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if removed := reopened.DeletePrefix("img1-"); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	if _, found := reopened.Get("img1-a"); found {
+		t.Error("Expected img1-a to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, diskFileName("img1-b"))); !os.IsNotExist(err) {
+		t.Error("Expected img1-b cache file to be removed")
+	}
+	if _, found := reopened.Get("img2-a"); !found {
+		t.Error("Expected img2-a to be kept")
+	}
+	if _, found := reopened.Get("img1/unsafe"); !found {
+		t.Error("Expected hashed key to be kept")
+	}
+}
+
+func TestDiskFileName(t *testing.T) {
+	if name := diskFileName("abc-123_x"); name != "abc-123_x"+diskFileExt {
+		t.Errorf("Expected safe key to be used as file name, got %q", name)
+	}
+
+	for _, key := range []string{"", "Key1", "a/b", "../x", diskHashPrefix + "abc", strings.Repeat("a", maxDiskKeyLength+1)} {
+		name := diskFileName(key)
+		if !strings.HasPrefix(name, diskHashPrefix) {
+			t.Errorf("Expected key %q to be hashed, got %q", key, name)
+		}
+		if _, ok := diskFileKey(name); ok {
+			t.Errorf("Expected hashed file name %q not to map back to a key", name)
+		}
+	}
+}
\ No newline at end of file
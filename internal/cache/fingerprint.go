@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// FingerprintVersion 识别指纹格式版本
+// 指纹字段或识别行为 (如预处理实现) 变化导致旧的缓存结果不再有效时递增
+const FingerprintVersion = 1
+
+// Fingerprint 识别指纹: 影响识别结果的全部选项，与图像数据一起生成缓存键
+// 预处理配置 (profile) 不单独记录，其管道、语言和 OCR 参数已解析到对应字段
+type Fingerprint struct {
+	Level            string `json:"level"`             // 版面分析层级 (纯文本识别为空)
+	Language         string `json:"language"`          // 识别语言
+	PageSegMode      int    `json:"page_seg_mode"`     // 实际使用的页面分割模式
+	EngineMode       int    `json:"engine_mode"`       // 引擎模式
+	Whitelist        string `json:"whitelist"`         // 实际使用的字符白名单
+	Preprocess       bool   `json:"preprocess"`        // 是否预处理
+	AutoMode         bool   `json:"auto_mode"`         // 是否按图像质量自动选择预处理步骤
+	Pipeline         string `json:"pipeline"`          // 显式指定的预处理管道 (规范表示)
+	PreprocessConfig string `json:"preprocess_config"` // 预处理配置摘要 (决定默认管道和步骤参数)
+	EngineVersion    string `json:"engine_version"`    // OCR 引擎版本
+	DataChecksum     string `json:"data_checksum"`     // 语言数据文件校验和
+}
+
+// Key 生成缓存键: "<图像哈希>-<指纹哈希>"，指纹哈希为指纹版本和指纹规范表示 (JSON) 的 SHA256 哈希
+// 同一图像的全部结果以 ImagePrefix 开头，可通过 Backend.DeletePrefix 一起删除
+// 不预处理时忽略预处理相关字段，使相同的识别结果共用缓存
+func (f Fingerprint) Key(data []byte) string {
+	if !f.Preprocess {
+		f.AutoMode = false
+		f.Pipeline = ""
+		f.PreprocessConfig = ""
+	}
+
+	canonical, _ := json.Marshal(f)
+	return ImagePrefix(ImageHash(data)) + GenerateKey(nil, fmt.Sprintf("fingerprint/v%d\x00", FingerprintVersion), string(canonical))
+}
+
+// ImageHash 图像数据的 SHA256 哈希 (十六进制)
+func ImageHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ImagePrefix 图像哈希对应的缓存键前缀
+func ImagePrefix(imageHash string) string {
+	return imageHash + "-"
+}
+
+// Digest 计算配置等值的摘要 (规范 JSON 的 SHA256)，用于指纹中的配置字段
+func Digest(v interface{}) string {
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
\ No newline at end of file
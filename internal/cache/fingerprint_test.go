@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFingerprint_Key(t *testing.T) {
+	data := []byte("test data")
+	base := Fingerprint{
+		Language:         "eng",
+		PageSegMode:      3,
+		Preprocess:       true,
+		AutoMode:         true,
+		PreprocessConfig: "abc",
+		EngineVersion:    "5.3.0",
+	}
+
+	if base.Key(data) != base.Key(data) {
+		t.Error("Expected same key for same fingerprint")
+	}
+
+	if base.Key(data) == base.Key([]byte("other data")) {
+		t.Error("Expected different key for different image data")
+	}
+
+	variants := map[string]func(f *Fingerprint){
+		"level":             func(f *Fingerprint) { f.Level = "word" },
+		"language":          func(f *Fingerprint) { f.Language = "chi_sim" },
+		"page_seg_mode":     func(f *Fingerprint) { f.PageSegMode = 6 },
+		"engine_mode":       func(f *Fingerprint) { f.EngineMode = 1 },
+		"whitelist":         func(f *Fingerprint) { f.Whitelist = "0123456789" },
+		"preprocess":        func(f *Fingerprint) { f.Preprocess = false },
+		"auto_mode":         func(f *Fingerprint) { f.AutoMode = false },
+		"pipeline":          func(f *Fingerprint) { f.Pipeline = "grayscale" },
+		"preprocess_config": func(f *Fingerprint) { f.PreprocessConfig = "def" },
+		"engine_version":    func(f *Fingerprint) { f.EngineVersion = "4.1.1" },
+		"data_checksum":     func(f *Fingerprint) { f.DataChecksum = "123" },
+	}
+
+	for name, change := range variants {
+		changed := base
+		change(&changed)
+		if changed.Key(data) == base.Key(data) {
+			t.Errorf("Expected different key when %s changes", name)
+		}
+	}
+}
+
+func TestFingerprint_KeyWithoutPreprocessing(t *testing.T) {
+	data := []byte("test data")
+	a := Fingerprint{Language: "eng", AutoMode: true, Pipeline: "grayscale", PreprocessConfig: "abc"}
+	b := Fingerprint{Language: "eng"}
+
+	if a.Key(data) != b.Key(data) {
+		t.Error("Expected preprocessing options to be ignored when preprocessing is disabled")
+	}
+}
+
+func TestFingerprint_KeyDiffersFromGenerateKey(t *testing.T) {
+	data := []byte("test data")
+	if (Fingerprint{}).Key(data) == GenerateKey(data) {
+		t.Error("Expected fingerprint key to differ from unversioned key")
+	}
+}
+
+func TestFingerprint_KeyImagePrefix(t *testing.T) {
+	data := []byte("test data")
+	prefix := ImagePrefix(ImageHash(data))
+
+	a := Fingerprint{Language: "eng"}.Key(data)
+	b := Fingerprint{Language: "chi_sim"}.Key(data)
+	if !strings.HasPrefix(a, prefix) || !strings.HasPrefix(b, prefix) {
+		t.Errorf("Expected keys %q and %q to start with image prefix %q", a, b, prefix)
+	}
+	if strings.HasPrefix(Fingerprint{}.Key([]byte("other data")), prefix) {
+		t.Error("Expected key of other image not to share the prefix")
+	}
+	if !safeDiskKey(a) {
+		t.Errorf("Expected fingerprint key %q to be usable as a disk file name", a)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	type settings struct {
+		Denoise  bool
+		Strength int
+	}
+
+	if Digest(settings{true, 5}) != Digest(settings{true, 5}) {
+		t.Error("Expected same digest for same value")
+	}
+	if Digest(settings{true, 5}) == Digest(settings{true, 7}) {
+		t.Error("Expected different digest for different value")
+	}
+	if Digest(make(chan int)) != "" {
+		t.Error("Expected empty digest for unsupported value")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Group 合并相同键的并发调用: 同一时间每个键只执行一次 fn，其余调用等待并共享结果
+// 用于缓存未命中时避免重复执行相同的识别，零值可直接使用
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call 正在执行的调用
+type call struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	dups    int                // 等待共享结果的调用数 (不含发起执行的调用)
+	waiters int                // 仍在等待结果的调用数 (含发起执行的调用)
+	cancel  context.CancelFunc // 取消 fn 的 ctx
+}
+
+// Do 执行 fn，或等待相同键正在执行的调用完成并返回其结果
+// fn 在独立的 goroutine 中执行，其 ctx 保留发起调用 ctx 中的值但不随之取消，只在所有调用都放弃等待时取消，
+// 因此发起调用超时或取消不会使其他仍在等待的调用失败
+// shared 表示结果是否由多个调用共享 (包括错误)；等待期间 ctx 取消时立即返回 ctx.Err()
+// 每个调用得到 OCR 错误的独立副本，调用方可以直接添加详细信息
+func (g *Group) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (value interface{}, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	c, leader := g.calls[key], false
+	if c != nil {
+		c.dups++
+		c.waiters++
+	} else {
+		leader = true
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = c
+		go g.run(runCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		g.mu.Lock()
+		shared = !leader || c.dups > 0
+		g.mu.Unlock()
+		return c.value, shared, copyError(c.err)
+	case <-ctx.Done():
+		return nil, g.leave(key, c, leader), ctx.Err()
+	}
+}
+
+// InFlight 正在执行且仍有调用等待的调用数
+func (g *Group) InFlight() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.calls)
+}
+
+// leave 调用放弃等待，最后一个调用离开时取消 fn 的 ctx 并移除调用记录 (之后相同键的调用重新执行)
+func (g *Group) leave(key string, c *call, leader bool) (shared bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+	return !leader || c.dups > 0
+}
+
+// run 执行 fn 并唤醒等待的调用 (fn panic 时等待的调用收到错误)
+func (g *Group) run(ctx context.Context, key string, c *call, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("In-flight call panicked", zap.String("key", key), zap.Any("panic", r), zap.Stack("stack"))
+			c.value, c.err = nil, fmt.Errorf("in-flight call panicked: %v", r)
+		}
+		g.finish(key, c)
+	}()
+
+	c.value, c.err = fn(ctx)
+}
+
+// copyError 复制共享的 OCR 错误，避免调用方并发修改同一个详细信息 map
+func copyError(err error) error {
+	if ocrErr, ok := err.(*ocrErrors.OCRError); ok {
+		return ocrErr.Clone()
+	}
+	return err
+}
+
+// finish 移除调用记录 (之后相同键的调用重新执行)，释放 fn 的 ctx 并唤醒等待的调用
+func (g *Group) finish(key string, c *call) {
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+
+	c.cancel()
+	close(c.done)
+}
\ No newline at end of file
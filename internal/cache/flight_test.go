@@ -0,0 +1,337 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+// waitForWaiters 等待指定数量的调用在 key 上等待
+func waitForWaiters(t *testing.T, group *Group, key string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		group.mu.Lock()
+		c := group.calls[key]
+		waiting := c != nil && c.dups == n
+		group.mu.Unlock()
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d calls to coalesce", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGroup_Coalesce(t *testing.T) {
+	var group Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return "result", nil
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	results := make([]interface{}, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, shared, err := group.Do(context.Background(), "key1", fn)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+			results[i] = value
+		}(i)
+	}
+
+	waitForWaiters(t, &group, "key1", n-1)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected fn to run once, ran %d times", calls.Load())
+	}
+	if sharedCount.Load() != n {
+		t.Errorf("Expected all %d calls to report shared, got %d", n, sharedCount.Load())
+	}
+	for i, value := range results {
+		if value != "result" {
+			t.Errorf("Call %d: expected result, got %v", i, value)
+		}
+	}
+	if group.InFlight() != 0 {
+		t.Errorf("Expected no in-flight calls, got %d", group.InFlight())
+	}
+}
+
+func TestGroup_SequentialCallsRunAgain(t *testing.T) {
+	var group Group
+	calls := 0
+
+	for i := 0; i < 3; i++ {
+		_, shared, _ := group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+			calls++
+			return nil, nil
+		})
+		if shared {
+			t.Error("Expected sequential call not to be shared")
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected fn to run 3 times, ran %d times", calls)
+	}
+}
+
+func TestGroup_ErrorShared(t *testing.T) {
+	var group Group
+	wantErr := errors.New("ocr failed")
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, wantErr
+	})
+	<-started
+
+	done := make(chan error)
+	go func() {
+		_, _, err := group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+			t.Error("Expected waiting call not to run fn")
+			return nil, nil
+		})
+		done <- err
+	}()
+
+	waitForWaiters(t, &group, "key1", 1)
+	close(release)
+
+	if err := <-done; !errors.Is(err, wantErr) {
+		t.Errorf("Expected shared error %v, got %v", wantErr, err)
+	}
+}
+
+func TestGroup_ErrorCopiedPerCaller(t *testing.T) {
+	var group Group
+	release := make(chan struct{})
+	shared := ocrErrors.New(ocrErrors.ErrOCREngineFailed, "OCR recognition failed").
+		WithDetails("engine", "tesseract")
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, shared
+	}
+
+	// 多个文档的相同页面合并执行，每个调用方在失败后添加自己的页码
+	const pages = 3
+	const callers = 4
+	var wg sync.WaitGroup
+	errs := make([][]error, pages)
+	for page := 0; page < pages; page++ {
+		errs[page] = make([]error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(page, i int) {
+				defer wg.Done()
+				_, _, err := group.Do(context.Background(), fmt.Sprintf("page%d", page), fn)
+
+				var ocrErr *ocrErrors.OCRError
+				if !errors.As(err, &ocrErr) {
+					t.Errorf("Expected OCR error, got %v", err)
+					return
+				}
+				errs[page][i] = ocrErr.WithDetails("page", page+1).WithDetails("caller", i)
+			}(page, i)
+		}
+	}
+
+	for page := 0; page < pages; page++ {
+		waitForWaiters(t, &group, fmt.Sprintf("page%d", page), callers-1)
+	}
+	close(release)
+	wg.Wait()
+
+	for page := range errs {
+		for i, err := range errs[page] {
+			ocrErr, ok := err.(*ocrErrors.OCRError)
+			if !ok {
+				continue
+			}
+			if ocrErr == shared {
+				t.Fatal("Expected each caller to get its own copy of the error")
+			}
+			if ocrErr.Details["page"] != page+1 || ocrErr.Details["caller"] != i {
+				t.Errorf("Page %d caller %d: got details from another caller %v", page+1, i, ocrErr.Details)
+			}
+			if ocrErr.Details["engine"] != "tesseract" {
+				t.Errorf("Expected copied error to keep original details, got %v", ocrErr.Details)
+			}
+		}
+	}
+	if len(shared.Details) != 1 {
+		t.Errorf("Expected shared error to be unchanged, got %v", shared.Details)
+	}
+}
+
+func TestGroup_WaiterContextCancelled(t *testing.T) {
+	var group Group
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	go group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return "result", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := group.Do(ctx, "key1", func(ctx context.Context) (interface{}, error) {
+		t.Error("Expected waiting call not to run fn")
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	if group.InFlight() != 1 {
+		t.Errorf("Expected the original call to keep running, in-flight %d", group.InFlight())
+	}
+}
+
+func TestGroup_DifferentKeys(t *testing.T) {
+	var group Group
+
+	v1, _, _ := group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) { return 1, nil })
+	v2, _, _ := group.Do(context.Background(), "key2", func(ctx context.Context) (interface{}, error) { return 2, nil })
+
+	if v1 != 1 || v2 != 2 {
+		t.Errorf("Expected 1 and 2, got %v and %v", v1, v2)
+	}
+}
+
+func TestGroup_LeaderDeadlineShorterThanWaiter(t *testing.T) {
+	var group Group
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		close(started)
+		select {
+		case <-release:
+			return "result", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	leaderErr := make(chan error)
+	go func() {
+		_, _, err := group.Do(leaderCtx, "key1", fn)
+		leaderErr <- err
+	}()
+	<-started
+
+	waiterDone := make(chan interface{})
+	go func() {
+		value, _, err := group.Do(context.Background(), "key1", fn)
+		if err != nil {
+			t.Errorf("Expected waiter to get the shared result, got %v", err)
+		}
+		waiterDone <- value
+	}()
+	waitForWaiters(t, &group, "key1", 1)
+
+	// 发起调用超时后，共享的执行继续为仍在等待的调用运行
+	if err := <-leaderErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected leader deadline exceeded, got %v", err)
+	}
+	close(release)
+
+	if value := <-waiterDone; value != "result" {
+		t.Errorf("Expected result, got %v", value)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected fn to run once, ran %d times", calls.Load())
+	}
+}
+
+func TestGroup_CancelledWhenAllCallersLeave(t *testing.T) {
+	var group Group
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, _, err := group.Do(ctx, "key1", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected fn context to be cancelled after all callers left")
+	}
+
+	// 之后相同键的调用重新执行
+	value, shared, err := group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+		return "again", nil
+	})
+	if err != nil || value != "again" || shared {
+		t.Errorf("Expected a new call, got %v, shared %v, err %v", value, shared, err)
+	}
+}
+
+func TestGroup_Panic(t *testing.T) {
+	var group Group
+
+	_, _, err := group.Do(context.Background(), "key1", func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("Expected panic error, got %v", err)
+	}
+	if group.InFlight() != 0 {
+		t.Errorf("Expected no in-flight calls, got %d", group.InFlight())
+	}
+}
\ No newline at end of file
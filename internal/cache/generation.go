@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+)
+
+// Generation 缓存失效代数，按图像哈希记录，零值可直接使用
+// 识别开始前记录缓存键对应图像的当前代数，写入结果时代数已变化 (期间该图像被失效，或缓存被清空) 则丢弃写入，
+// 避免进行中的识别写回旧结果；失效一个图像不影响其他图像进行中的识别
+type Generation struct {
+	mu     sync.RWMutex
+	seq    uint64            // 最近分配的代数
+	all    uint64            // 最近一次全部失效 (清空) 的代数
+	images map[string]uint64 // 图像哈希 -> 最近一次失效的代数 (全部失效时清空，只保留晚于 all 的记录)
+}
+
+// Current 缓存键 (Fingerprint.Key) 对应图像的当前代数
+func (g *Generation) Current(key string) uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.currentLocked(key)
+}
+
+func (g *Generation) currentLocked(key string) uint64 {
+	if gen, ok := g.images[keyImageHash(key)]; ok {
+		return gen
+	}
+	return g.all
+}
+
+// Set 缓存键对应图像的代数仍为 gen 时写入缓存，返回是否写入
+func (g *Generation) Set(backend Backend, gen uint64, key string, value interface{}) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.currentLocked(key) != gen {
+		return false
+	}
+	backend.Set(key, value)
+	return true
+}
+
+// Invalidate 增加指定图像的代数并执行 fn (删除这些图像的缓存条目)，执行期间不会有写入
+func (g *Generation) Invalidate(imageHashes []string, fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	if g.images == nil {
+		g.images = make(map[string]uint64)
+	}
+	for _, hash := range imageHashes {
+		g.images[hash] = g.seq
+	}
+	fn()
+}
+
+// InvalidateAll 增加全部图像的代数并执行 fn (清空缓存)，执行期间不会有写入
+func (g *Generation) InvalidateAll(fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	g.all = g.seq
+	g.images = nil
+	fn()
+}
+
+// keyImageHash 缓存键中的图像哈希 (ImagePrefix 之前的部分)
+func keyImageHash(key string) string {
+	hash, _, _ := strings.Cut(key, "-")
+	return hash
+}
\ No newline at end of file
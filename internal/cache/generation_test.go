@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGeneration_DropsWritesStartedBeforeInvalidate(t *testing.T) {
+	var gen Generation
+	cache := NewCache(10, 0, time.Hour, true)
+
+	// 识别开始后缓存被失效，结束时的写入被丢弃
+	started := gen.Current("img-key")
+	gen.Invalidate([]string{"img"}, func() {
+		cache.DeletePrefix("img-")
+	})
+
+	if gen.Set(cache, started, "img-key", "stale") {
+		t.Error("Expected write started before invalidate to be dropped")
+	}
+	if _, found := cache.Get("img-key"); found {
+		t.Error("Expected stale result not to be cached")
+	}
+
+	// 失效之后开始的识别正常写入
+	if !gen.Set(cache, gen.Current("img-key"), "img-key", "fresh") {
+		t.Error("Expected write started after invalidate to be stored")
+	}
+	if value, _ := cache.Get("img-key"); value != "fresh" {
+		t.Errorf("Expected fresh, got %v", value)
+	}
+}
+
+func TestGeneration_InvalidateOnlyAffectsGivenImages(t *testing.T) {
+	var gen Generation
+	cache := NewCache(10, 0, time.Hour, true)
+
+	// 失效 img1 不影响 img2 进行中的识别
+	started1 := gen.Current("img1-key")
+	started2 := gen.Current("img2-key")
+	gen.Invalidate([]string{"img1"}, func() {
+		cache.DeletePrefix("img1-")
+	})
+
+	if gen.Set(cache, started1, "img1-key", "stale") {
+		t.Error("Expected write for invalidated image to be dropped")
+	}
+	if !gen.Set(cache, started2, "img2-key", "value") {
+		t.Error("Expected write for other image to be stored")
+	}
+
+	// 清空缓存使全部图像进行中的识别失效
+	started1 = gen.Current("img1-key")
+	started2 = gen.Current("img2-key")
+	gen.InvalidateAll(cache.Clear)
+
+	if gen.Set(cache, started1, "img1-key", "stale") || gen.Set(cache, started2, "img2-key", "stale") {
+		t.Error("Expected writes started before clear to be dropped")
+	}
+	if cache.Size() != 0 {
+		t.Errorf("Expected empty cache, got %d entries", cache.Size())
+	}
+
+	// 清空之后失效的图像代数仍然变化
+	started1 = gen.Current("img1-key")
+	gen.Invalidate([]string{"img1"}, func() {})
+	if gen.Current("img1-key") == started1 {
+		t.Error("Expected invalidate after clear to change generation")
+	}
+}
+
+func TestGeneration_InvalidateWaitsForWrites(t *testing.T) {
+	var gen Generation
+	cache := NewCache(10, 0, time.Hour, true)
+
+	for i := 0; i < 100; i++ {
+		started := gen.Current("img-key")
+		done := make(chan bool)
+		go func() {
+			done <- gen.Set(cache, started, "img-key", "value")
+		}()
+		gen.Invalidate([]string{"img"}, func() {
+			cache.DeletePrefix("img-")
+		})
+
+		// 写入要么在失效之前完成 (随后被删除)，要么被丢弃
+		<-done
+		if _, found := cache.Get("img-key"); found {
+			t.Fatal("Expected no entry to survive invalidate")
+		}
+	}
+}
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,27 +15,83 @@ type Config struct {
 	OCR           OCRConfig           `yaml:"ocr"`
 	Preprocessing PreprocessingConfig `yaml:"preprocessing"`
 	Performance   PerformanceConfig   `yaml:"performance"`
+	Security      SecurityConfig      `yaml:"security"`
 	Logger        LoggerConfig        `yaml:"logger"`
 }
 
 // ServerConfig MCP Server 配置
 type ServerConfig struct {
-	Name        string `yaml:"name"`
-	Version     string `yaml:"version"`
-	Description string `yaml:"description"`
+	Name            string     `yaml:"name"`
+	Version         string     `yaml:"version"`
+	Description     string     `yaml:"description"`
+	Transport       string     `yaml:"transport"`        // 传输方式: stdio, http (Streamable HTTP), sse
+	Address         string     `yaml:"address"`          // HTTP/SSE 监听地址
+	Path            string     `yaml:"path"`             // HTTP/SSE 端点路径
+	ShutdownTimeout int        `yaml:"shutdown_timeout"` // 优雅关闭超时 (秒，未配置或 0 时为 30)
+	Auth            AuthConfig `yaml:"auth"`             // HTTP/SSE 认证配置
 }
 
+// AuthConfig 网络传输认证配置 (stdio 传输不认证)
+type AuthConfig struct {
+	Enabled  bool      `yaml:"enabled"`   // 是否启用认证
+	APIKeys  []APIKey  `yaml:"api_keys"`  // 静态 API Key
+	HMACKeys []HMACKey `yaml:"hmac_keys"` // HMAC 签名 Bearer Token 的密钥
+}
+
+// APIKey 静态 API Key
+type APIKey struct {
+	Name  string   `yaml:"name"`  // 调用方名称 (用于日志)
+	Key   string   `yaml:"key"`   // API Key
+	Tools []string `yaml:"tools"` // 允许调用的工具 (为空表示全部)
+}
+
+// HMACKey HMAC-SHA256 Token 签名密钥
+type HMACKey struct {
+	ID     string   `yaml:"id"`     // 密钥 ID (Token 中的 kid)
+	Secret string   `yaml:"secret"` // 签名密钥
+	Tools  []string `yaml:"tools"`  // 允许调用的工具 (为空表示全部)
+}
+
+// 传输方式
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+)
+
+// Unlimited 用于数量和大小上限，表示不限制 (上限未配置或为 0 时使用默认值)
+const Unlimited = -1
+
 // OCRConfig OCR 引擎配置
 type OCRConfig struct {
-	Engine          string   `yaml:"engine"`           // tesseract
-	Language        string   `yaml:"language"`         // eng+chi_sim+chi_tra+jpn
-	DataPath        string   `yaml:"data_path"`        // tessdata 路径
-	PageSegMode     int      `yaml:"page_seg_mode"`    // 页面分割模式 (3=全自动)
-	EngineMode      int      `yaml:"engine_mode"`      // 引擎模式 (3=默认)
-	Whitelist       string   `yaml:"whitelist"`        // 字符白名单
-	SupportedLangs  []string `yaml:"supported_langs"`  // 支持的语言列表
-	MaxImageSize    int64    `yaml:"max_image_size"`   // 最大图像大小(字节)
-	Timeout         int      `yaml:"timeout"`          // OCR 超时时间(秒)
+	Engine         string     `yaml:"engine"`          // tesseract
+	Language       string     `yaml:"language"`        // eng+chi_sim+chi_tra+jpn
+	DataPath       string     `yaml:"data_path"`       // tessdata 路径
+	PageSegMode    int        `yaml:"page_seg_mode"`   // 页面分割模式 (3=全自动)
+	EngineMode     int        `yaml:"engine_mode"`     // 引擎模式 (3=默认)
+	Whitelist      string     `yaml:"whitelist"`       // 字符白名单
+	SupportedLangs []string   `yaml:"supported_langs"` // 支持的语言列表
+	MaxImageSize   int64      `yaml:"max_image_size"`  // 最大图像大小(字节)
+	MaxPixels      int64      `yaml:"max_pixels"`      // 最大像素数 (宽 x 高，-1 表示不限制)
+	AllowedFormats []string   `yaml:"allowed_formats"` // 允许的输入格式 (未配置时使用默认列表，[] 表示全部可识别格式)
+	Timeout        int        `yaml:"timeout"`         // OCR 超时时间(秒)
+	MaxAbandoned   int        `yaml:"max_abandoned"`   // 超时后仍在后台运行的识别数上限，达到时拒绝新的识别 (0 表示等于 worker_pool_size)
+	PDF            PDFConfig  `yaml:"pdf"`             // PDF 输入配置
+	TIFF           TIFFConfig `yaml:"tiff"`            // 多页 TIFF 输入配置
+}
+
+// PDFConfig PDF 输入配置
+type PDFConfig struct {
+	Renderer string `yaml:"renderer"`  // 栅格化命令 (poppler pdftoppm)
+	Info     string `yaml:"info"`      // 读取页数和页面尺寸的命令 (poppler pdfinfo)
+	DPI      int    `yaml:"dpi"`       // 渲染分辨率
+	MaxPages int    `yaml:"max_pages"` // 最大页数，超过时拒绝 (-1 表示不限制)
+}
+
+// TIFFConfig 多页 TIFF 输入配置
+type TIFFConfig struct {
+	MaxPages int   `yaml:"max_pages"` // 最大页数 (-1 表示不限制)
+	MaxBytes int64 `yaml:"max_bytes"` // 拆分后各页的总大小上限 (字节，-1 表示不限制)
 }
 
 // PreprocessingConfig 图像预处理配置
@@ -57,17 +114,54 @@ type PreprocessingConfig struct {
 		Sharpness  float64 `yaml:"sharpness"`  // 清晰度阈值
 		Contrast   float64 `yaml:"contrast"`   // 对比度阈值
 		Brightness float64 `yaml:"brightness"` // 亮度阈值 (最小值)
+		MinDPI     float64 `yaml:"min_dpi"`    // OCR 适用性评估的最低分辨率 (0 表示不检查)
 	} `yaml:"quality_thresholds"`
+	Profiles map[string]PreprocessingProfile `yaml:"profiles"` // 命名的预处理配置，通过 profile 参数选择
+}
+
+// PreprocessingProfile 针对某类文档 (如收据、书籍扫描、截图) 的预处理管道和 OCR 参数
+type PreprocessingProfile struct {
+	Description string                   `yaml:"description"`   // 描述
+	Pipeline    []map[string]interface{} `yaml:"pipeline"`      // 预处理步骤，格式同 pipeline 参数 (为空时按全局配置选择)
+	Language    string                   `yaml:"language"`      // 识别语言 (为空时使用 ocr.language)
+	PageSegMode *int                     `yaml:"page_seg_mode"` // 页面分割模式 (为空时使用 ocr.page_seg_mode)
+	Whitelist   string                   `yaml:"whitelist"`     // 字符白名单 (为空时使用 ocr.whitelist)
 }
 
 // PerformanceConfig 性能配置
 type PerformanceConfig struct {
-	WorkerPoolSize  int  `yaml:"worker_pool_size"`  // Worker 池大小
-	QueueSize       int  `yaml:"queue_size"`        // 任务队列大小
-	CacheEnabled    bool `yaml:"cache_enabled"`     // 是否启用缓存
-	CacheSize       int  `yaml:"cache_size"`        // 缓存大小
-	CacheTTL        int  `yaml:"cache_ttl"`         // 缓存 TTL (秒)
-	ResourcePooling bool `yaml:"resource_pooling"`  // 是否启用资源池
+	WorkerPoolSize  int    `yaml:"worker_pool_size"`  // Worker 池大小
+	QueueSize       int    `yaml:"queue_size"`        // 任务队列大小
+	CacheEnabled    bool   `yaml:"cache_enabled"`     // 是否启用缓存
+	CacheSize       int    `yaml:"cache_size"`        // 缓存条目数上限
+	CacheTTL        int    `yaml:"cache_ttl"`         // 缓存 TTL (秒，0 表示不过期)
+	CacheBackend    string `yaml:"cache_backend"`     // 缓存后端: memory (默认) 或 disk (重启后保留)
+	CacheDir        string `yaml:"cache_dir"`         // 磁盘缓存目录 (为空时使用用户缓存目录下的 mcp-ocr-server)
+	CacheMaxBytes   int64  `yaml:"cache_max_bytes"`   // 缓存总大小上限 (字节，0 表示不限制)
+	ResourcePooling bool   `yaml:"resource_pooling"`  // 是否启用资源池
+	JobTTL          int    `yaml:"job_ttl"`           // 异步任务结束后的保留时间 (秒，未配置或 0 时为 3600)
+	MaxJobs         int    `yaml:"max_jobs"`          // 同时保留的异步任务数上限
+	JobConcurrency  int    `yaml:"job_concurrency"`   // 异步任务同时在 Worker 池中排队和执行的任务项数上限 (0 表示 worker_pool_size)
+	ResultStoreSize int    `yaml:"result_store_size"` // 保留的识别结果数 (供 MCP 资源读取，0 表示不保留)
+	ResultMaxBytes  int64  `yaml:"result_max_bytes"`  // 保留结果 (含原始输入) 的总大小上限 (字节，0 表示不限制)
+	ResultTTL       int    `yaml:"result_ttl"`        // 识别结果保留时间 (秒)
+}
+
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	AllowedRoots []string       `yaml:"allowed_roots"` // 允许读写的根目录 (为空表示不限制)
+	URLFetch     URLFetchConfig `yaml:"url_fetch"`     // 远程图像 URL 下载配置
+	AdminTools   bool           `yaml:"admin_tools"`   // 是否提供管理工具 (ocr_cache_stats、ocr_cache_invalidate、ocr_cache_clear)
+}
+
+// URLFetchConfig 远程图像 URL 下载配置
+type URLFetchConfig struct {
+	Enabled      bool     `yaml:"enabled"`       // 是否允许通过 URL 输入图像 (默认关闭，服务器会向外发起请求)
+	Timeout      int      `yaml:"timeout"`       // 下载超时 (秒)
+	MaxRedirects int      `yaml:"max_redirects"` // 最大重定向次数
+	AllowedHosts []string `yaml:"allowed_hosts"` // 允许的主机 (为空表示全部)，支持 "*.example.com" 和 CIDR
+	DeniedHosts  []string `yaml:"denied_hosts"`  // 禁止的主机，优先于 allowed_hosts
+	AllowPrivate bool     `yaml:"allow_private"` // 是否允许访问回环、私有和链路本地等内网地址
 }
 
 // LoggerConfig 日志配置
@@ -91,6 +185,9 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// 未配置的项使用默认值
+	cfg.applyDefaults()
+
 	// 验证配置
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
@@ -104,8 +201,77 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// applyDefaults 为未配置 (值为 0 或 nil) 的项设置默认值 (取自 GetDefault)
+func (c *Config) applyDefaults() {
+	defaults := GetDefault()
+
+	// 0 会使优雅关闭立即超时，中断进行中的 HTTP/SSE 请求
+	if c.Server.ShutdownTimeout == 0 {
+		c.Server.ShutdownTimeout = defaults.Server.ShutdownTimeout
+	}
+
+	// 0 会使已结束的任务永不清理
+	if c.Performance.JobTTL == 0 {
+		c.Performance.JobTTL = defaults.Performance.JobTTL
+	}
+
+	// 输入限制未配置时使用默认值，不限制需要显式配置为 Unlimited
+	if c.OCR.MaxPixels == 0 {
+		c.OCR.MaxPixels = defaults.OCR.MaxPixels
+	}
+
+	if c.OCR.PDF.MaxPages == 0 {
+		c.OCR.PDF.MaxPages = defaults.OCR.PDF.MaxPages
+	}
+	if c.OCR.TIFF.MaxPages == 0 {
+		c.OCR.TIFF.MaxPages = defaults.OCR.TIFF.MaxPages
+	}
+	if c.OCR.TIFF.MaxBytes == 0 {
+		c.OCR.TIFF.MaxBytes = defaults.OCR.TIFF.MaxBytes
+	}
+
+	// 未配置 (nil) 时使用默认列表，显式配置的空列表表示允许全部可识别格式
+	if c.OCR.AllowedFormats == nil {
+		c.OCR.AllowedFormats = defaults.OCR.AllowedFormats
+	}
+}
+
 // Validate 验证配置
 func (c *Config) Validate() error {
+	// 验证服务器配置
+	switch c.Server.Transport {
+	case "", TransportStdio:
+	case TransportHTTP, TransportSSE:
+		if c.Server.Address == "" {
+			return fmt.Errorf("server address is required for %s transport", c.Server.Transport)
+		}
+		if !strings.HasPrefix(c.Server.Path, "/") {
+			return fmt.Errorf("invalid server path: %q", c.Server.Path)
+		}
+	default:
+		return fmt.Errorf("unsupported server transport: %s", c.Server.Transport)
+	}
+
+	if c.Server.ShutdownTimeout < 0 {
+		return fmt.Errorf("invalid shutdown_timeout: %d", c.Server.ShutdownTimeout)
+	}
+
+	if c.Server.Auth.Enabled {
+		if len(c.Server.Auth.APIKeys) == 0 && len(c.Server.Auth.HMACKeys) == 0 {
+			return fmt.Errorf("auth is enabled but no api_keys or hmac_keys are configured")
+		}
+		for _, key := range c.Server.Auth.APIKeys {
+			if key.Key == "" {
+				return fmt.Errorf("api key %q is empty", key.Name)
+			}
+		}
+		for _, key := range c.Server.Auth.HMACKeys {
+			if key.ID == "" || key.Secret == "" {
+				return fmt.Errorf("hmac key requires id and secret")
+			}
+		}
+	}
+
 	// 验证 OCR 配置
 	if c.OCR.Engine != "tesseract" {
 		return fmt.Errorf("unsupported OCR engine: %s", c.OCR.Engine)
@@ -119,6 +285,43 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid timeout: %d", c.OCR.Timeout)
 	}
 
+	if c.OCR.MaxAbandoned < 0 {
+		return fmt.Errorf("invalid max_abandoned: %d", c.OCR.MaxAbandoned)
+	}
+
+	if c.OCR.MaxPixels < Unlimited {
+		return fmt.Errorf("invalid max_pixels: %d", c.OCR.MaxPixels)
+	}
+
+	if c.OCR.PDF.DPI < 0 {
+		return fmt.Errorf("invalid pdf dpi: %d", c.OCR.PDF.DPI)
+	}
+
+	if c.OCR.PDF.MaxPages < Unlimited {
+		return fmt.Errorf("invalid pdf max_pages: %d", c.OCR.PDF.MaxPages)
+	}
+
+	if c.OCR.TIFF.MaxPages < Unlimited {
+		return fmt.Errorf("invalid tiff max_pages: %d", c.OCR.TIFF.MaxPages)
+	}
+
+	if c.OCR.TIFF.MaxBytes < Unlimited {
+		return fmt.Errorf("invalid tiff max_bytes: %d", c.OCR.TIFF.MaxBytes)
+	}
+
+	if c.Preprocessing.QualityThresholds.MinDPI < 0 {
+		return fmt.Errorf("invalid min_dpi: %v", c.Preprocessing.QualityThresholds.MinDPI)
+	}
+
+	for name, profile := range c.Preprocessing.Profiles {
+		if name == "" {
+			return fmt.Errorf("preprocessing profile name must not be empty")
+		}
+		if psm := profile.PageSegMode; psm != nil && (*psm < 0 || *psm > 13) {
+			return fmt.Errorf("invalid page_seg_mode in profile %q: %d", name, *psm)
+		}
+	}
+
 	// 验证性能配置
 	if c.Performance.WorkerPoolSize <= 0 {
 		return fmt.Errorf("invalid worker_pool_size: %d", c.Performance.WorkerPoolSize)
@@ -128,10 +331,45 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid queue_size: %d", c.Performance.QueueSize)
 	}
 
+	if c.Performance.JobTTL < 0 || c.Performance.MaxJobs < 0 || c.Performance.JobConcurrency < 0 {
+		return fmt.Errorf("invalid job settings: job_ttl=%d, max_jobs=%d, job_concurrency=%d",
+			c.Performance.JobTTL, c.Performance.MaxJobs, c.Performance.JobConcurrency)
+	}
+
+	if c.Performance.ResultStoreSize < 0 || c.Performance.ResultMaxBytes < 0 || c.Performance.ResultTTL < 0 {
+		return fmt.Errorf("invalid result store settings: result_store_size=%d, result_max_bytes=%d, result_ttl=%d",
+			c.Performance.ResultStoreSize, c.Performance.ResultMaxBytes, c.Performance.ResultTTL)
+	}
+
 	if c.Performance.CacheEnabled && c.Performance.CacheSize <= 0 {
 		return fmt.Errorf("invalid cache_size: %d", c.Performance.CacheSize)
 	}
 
+	switch c.Performance.CacheBackend {
+	case "", "memory", "disk":
+	default:
+		return fmt.Errorf("invalid cache_backend: %s (must be memory or disk)", c.Performance.CacheBackend)
+	}
+
+	if c.Performance.CacheMaxBytes < 0 {
+		return fmt.Errorf("invalid cache_max_bytes: %d", c.Performance.CacheMaxBytes)
+	}
+
+	// 验证安全配置
+	for _, root := range c.Security.AllowedRoots {
+		if root == "" {
+			return fmt.Errorf("allowed_roots must not contain empty paths")
+		}
+	}
+
+	if c.Security.URLFetch.Enabled && c.Security.URLFetch.Timeout <= 0 {
+		return fmt.Errorf("invalid url_fetch timeout: %d", c.Security.URLFetch.Timeout)
+	}
+
+	if c.Security.URLFetch.MaxRedirects < 0 {
+		return fmt.Errorf("invalid url_fetch max_redirects: %d", c.Security.URLFetch.MaxRedirects)
+	}
+
 	// 验证日志配置
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLevels[c.Logger.Level] {
@@ -157,6 +395,15 @@ func (c *Config) ProcessPaths() error {
 		c.OCR.DataPath = absPath
 	}
 
+	// 处理磁盘缓存目录
+	if c.Performance.CacheDir != "" {
+		absPath, err := filepath.Abs(c.Performance.CacheDir)
+		if err != nil {
+			return fmt.Errorf("invalid cache_dir: %w", err)
+		}
+		c.Performance.CacheDir = absPath
+	}
+
 	// 处理日志输出路径
 	if c.Logger.OutputPath != "" && c.Logger.OutputPath != "stdout" && c.Logger.OutputPath != "stderr" {
 		absPath, err := filepath.Abs(c.Logger.OutputPath)
@@ -179,9 +426,13 @@ func (c *Config) ProcessPaths() error {
 func GetDefault() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Name:        "mcp-ocr-server",
-			Version:     "1.0.0",
-			Description: "Production-grade OCR MCP Server with intelligent preprocessing",
+			Name:            "mcp-ocr-server",
+			Version:         "1.0.0",
+			Description:     "Production-grade OCR MCP Server with intelligent preprocessing",
+			Transport:       TransportStdio,
+			Address:         "127.0.0.1:8080",
+			Path:            "/mcp",
+			ShutdownTimeout: 30,
 		},
 		OCR: OCRConfig{
 			Engine:         "tesseract",
@@ -192,7 +443,19 @@ func GetDefault() *Config {
 			Whitelist:      "",
 			SupportedLangs: []string{"eng", "chi_sim", "chi_tra", "jpn"},
 			MaxImageSize:   10 * 1024 * 1024, // 10MB
+			MaxPixels:      100000000,        // 1 亿像素
+			AllowedFormats: []string{"png", "jpeg", "tiff", "bmp", "webp", "pdf"},
 			Timeout:        30,
+			PDF: PDFConfig{
+				Renderer: "pdftoppm",
+				Info:     "pdfinfo",
+				DPI:      300,
+				MaxPages: 100,
+			},
+			TIFF: TIFFConfig{
+				MaxPages: 100,
+				MaxBytes: 256 * 1024 * 1024, // 256MB
+			},
 		},
 		Preprocessing: PreprocessingConfig{
 			Enabled:           true,
@@ -216,7 +479,21 @@ func GetDefault() *Config {
 			CacheEnabled:    true,
 			CacheSize:       100,
 			CacheTTL:        3600,
+			CacheBackend:    "memory",
+			CacheMaxBytes:   512 * 1024 * 1024, // 512MB
 			ResourcePooling: true,
+			JobTTL:          3600,
+			MaxJobs:         100,
+			ResultStoreSize: 100,
+			ResultMaxBytes:  256 * 1024 * 1024, // 256MB
+			ResultTTL:       3600,
+		},
+		Security: SecurityConfig{
+			URLFetch: URLFetchConfig{
+				Enabled:      false,
+				Timeout:      15,
+				MaxRedirects: 3,
+			},
 		},
 		Logger: LoggerConfig{
 			Level:      "info",
@@ -0,0 +1,308 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrURLNotAllowed URL 的协议、主机或解析后的地址不被允许
+	ErrURLNotAllowed = errors.New("url not allowed")
+	// ErrContentTooLarge 响应内容超过大小限制
+	ErrContentTooLarge = errors.New("content too large")
+	// ErrContentType 响应的 Content-Type 不是图像或 PDF
+	ErrContentType = errors.New("unsupported content type")
+)
+
+// reservedPrefixes 默认禁止访问的地址段 (防止 SSRF 访问内网和元数据服务)
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64
+}
+
+// URLFetcherOptions 远程图像下载选项
+type URLFetcherOptions struct {
+	Timeout      time.Duration // 整个请求 (含读取响应) 的超时
+	MaxSize      int64         // 最大响应大小 (字节)
+	MaxRedirects int           // 最大重定向次数
+	AllowedHosts []string      // 允许的主机 (为空表示全部)，支持 "example.com"、"*.example.com" 和 CIDR
+	DeniedHosts  []string      // 禁止的主机，格式同 AllowedHosts，优先于 AllowedHosts
+	AllowPrivate bool          // 是否允许访问回环、私有和链路本地等内网地址
+}
+
+// URLFetcher 通过 HTTP(S) 下载远程图像，限制大小、内容类型和可访问的主机
+type URLFetcher struct {
+	client  *http.Client
+	options URLFetcherOptions
+	allowed hostMatcher
+	denied  hostMatcher
+}
+
+// NewURLFetcher 创建远程图像下载器
+func NewURLFetcher(options URLFetcherOptions) (*URLFetcher, error) {
+	allowed, err := parseHostMatcher(options.AllowedHosts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed_hosts: %w", err)
+	}
+	denied, err := parseHostMatcher(options.DeniedHosts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denied_hosts: %w", err)
+	}
+
+	fetcher := &URLFetcher{
+		options: options,
+		allowed: allowed,
+		denied:  denied,
+	}
+
+	// 在建立连接时检查 DNS 解析后的实际地址，避免通过 DNS 重绑定绕过主机检查
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return fetcher.checkAddress(address)
+		},
+	}
+
+	fetcher.client = &http.Client{
+		Timeout: options.Timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: options.Timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > options.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", options.MaxRedirects)
+			}
+			return fetcher.checkURL(req.URL)
+		},
+	}
+
+	return fetcher, nil
+}
+
+// Fetch 下载图像数据，返回内容和响应的 Content-Type
+func (f *URLFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("Accept", "image/*, application/pdf")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// 重定向或连接阶段的地址检查失败
+		if errors.Is(err, ErrURLNotAllowed) {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("failed to fetch url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch url: unexpected status %s", resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !allowedContentType(contentType) {
+		return nil, contentType, fmt.Errorf("%w: %s", ErrContentType, contentType)
+	}
+
+	if f.options.MaxSize > 0 && resp.ContentLength > f.options.MaxSize {
+		return nil, contentType, fmt.Errorf("%w: %d bytes", ErrContentTooLarge, resp.ContentLength)
+	}
+
+	// 边读边限制大小 (Content-Length 可能缺失或不准确)
+	reader := io.Reader(resp.Body)
+	if f.options.MaxSize > 0 {
+		reader = io.LimitReader(resp.Body, f.options.MaxSize+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, contentType, fmt.Errorf("failed to read response: %w", err)
+	}
+	if f.options.MaxSize > 0 && int64(len(data)) > f.options.MaxSize {
+		return nil, contentType, fmt.Errorf("%w: more than %d bytes", ErrContentTooLarge, f.options.MaxSize)
+	}
+
+	return data, contentType, nil
+}
+
+// checkURL 检查协议和主机名
+func (f *URLFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrURLNotAllowed, u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrURLNotAllowed)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in url", ErrURLNotAllowed)
+	}
+
+	if f.denied.matchHost(host) {
+		return fmt.Errorf("%w: host %s is denied", ErrURLNotAllowed, host)
+	}
+	if !f.allowed.empty() && !f.allowed.matchHost(host) {
+		return fmt.Errorf("%w: host %s is not in allowed hosts", ErrURLNotAllowed, host)
+	}
+
+	return nil
+}
+
+// checkAddress 检查实际连接的 IP 地址
+func (f *URLFetcher) checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %s", ErrURLNotAllowed, address)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %s", ErrURLNotAllowed, address)
+	}
+	ip = ip.Unmap()
+
+	if f.denied.matchIP(ip) {
+		return fmt.Errorf("%w: address %s is denied", ErrURLNotAllowed, ip)
+	}
+
+	// 显式允许的地址段 (如内网图床) 不受内网地址限制
+	if f.allowed.matchIP(ip) || f.options.AllowPrivate {
+		return nil
+	}
+
+	if isReservedAddr(ip) {
+		return fmt.Errorf("%w: address %s is in a private or reserved range", ErrURLNotAllowed, ip)
+	}
+
+	return nil
+}
+
+// isReservedAddr 判断是否为回环、私有、链路本地、组播或其他保留地址
+func isReservedAddr(ip netip.Addr) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedContentType 判断 Content-Type 是否可能为图像或 PDF (实际格式由魔数检测确定)
+func allowedContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "image/") ||
+		mediaType == "application/pdf" ||
+		mediaType == "application/octet-stream"
+}
+
+// hostMatcher 主机名和地址段匹配规则
+type hostMatcher struct {
+	hosts    map[string]bool // 精确匹配的主机名或 IP
+	suffixes []string        // "*.example.com" 形式的后缀 (".example.com")
+	prefixes []netip.Prefix  // CIDR 地址段
+}
+
+// parseHostMatcher 解析主机规则列表
+func parseHostMatcher(patterns []string) (hostMatcher, error) {
+	matcher := hostMatcher{hosts: make(map[string]bool)}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "":
+			return matcher, fmt.Errorf("empty host pattern")
+		case strings.Contains(pattern, "/"):
+			prefix, err := netip.ParsePrefix(pattern)
+			if err != nil {
+				return matcher, fmt.Errorf("invalid CIDR %q: %w", pattern, err)
+			}
+			matcher.prefixes = append(matcher.prefixes, prefix.Masked())
+		case strings.HasPrefix(pattern, "*."):
+			matcher.suffixes = append(matcher.suffixes, pattern[1:])
+		default:
+			matcher.hosts[strings.Trim(pattern, "[]")] = true
+		}
+	}
+
+	return matcher, nil
+}
+
+// empty 是否没有任何规则
+func (m hostMatcher) empty() bool {
+	return len(m.hosts) == 0 && len(m.suffixes) == 0 && len(m.prefixes) == 0
+}
+
+// matchHost 匹配 URL 中的主机名 (IP 形式的主机也会匹配 CIDR)
+func (m hostMatcher) matchHost(host string) bool {
+	if m.hosts[host] {
+		return true
+	}
+
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return m.matchIP(ip.Unmap())
+	}
+	return false
+}
+
+// matchIP 匹配实际连接的 IP 地址
+func (m hostMatcher) matchIP(ip netip.Addr) bool {
+	if m.hosts[ip.String()] {
+		return true
+	}
+
+	for _, prefix := range m.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
@@ -0,0 +1,268 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format 输入文件格式 (根据文件头魔数识别)
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpeg"
+	FormatTIFF Format = "tiff"
+	FormatBMP  Format = "bmp"
+	FormatWebP Format = "webp"
+	FormatGIF  Format = "gif"
+	FormatPDF  Format = "pdf"
+)
+
+// Formats 可识别的全部格式
+var Formats = []Format{FormatPNG, FormatJPEG, FormatTIFF, FormatBMP, FormatWebP, FormatGIF, FormatPDF}
+
+var (
+	// ErrUnknownFormat 无法识别的文件格式
+	ErrUnknownFormat = errors.New("unknown image format")
+	// ErrMalformedHeader 文件头不完整或损坏，无法读取图像尺寸
+	ErrMalformedHeader = errors.New("malformed image header")
+)
+
+// ParseFormat 解析格式名称 (不区分大小写，jpg/tif 视为 jpeg/tiff)
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "png":
+		return FormatPNG, nil
+	case "jpeg", "jpg":
+		return FormatJPEG, nil
+	case "tiff", "tif":
+		return FormatTIFF, nil
+	case "bmp":
+		return FormatBMP, nil
+	case "webp":
+		return FormatWebP, nil
+	case "gif":
+		return FormatGIF, nil
+	case "pdf":
+		return FormatPDF, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", name)
+	}
+}
+
+// DetectFormat 根据文件头魔数识别格式，无法识别时返回空字符串
+func DetectFormat(data []byte) Format {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return FormatJPEG
+	case IsTIFF(data):
+		return FormatTIFF
+	case bytes.HasPrefix(data, []byte("BM")) && len(data) >= 26:
+		return FormatBMP
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return FormatWebP
+	case bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a")):
+		return FormatGIF
+	case IsPDF(data):
+		return FormatPDF
+	default:
+		return ""
+	}
+}
+
+// ImageInfo 从文件头读取的图像信息
+type ImageInfo struct {
+	Format Format // 文件格式
+	Width  int    // 声明的宽度 (像素，PDF 为 0)
+	Height int    // 声明的高度 (像素，PDF 为 0)
+}
+
+// ExceedsPixels 声明的像素数 (宽 x 高) 是否超过 maxPixels
+// 不计算乘积: PNG、TIFF 声明的 32 位尺寸相乘会溢出 int64
+func (i ImageInfo) ExceedsPixels(maxPixels int64) bool {
+	if i.Width <= 0 || i.Height <= 0 {
+		return false
+	}
+	return int64(i.Width) > maxPixels/int64(i.Height)
+}
+
+// Inspect 识别文件格式并读取文件头中声明的尺寸 (不解码像素数据)
+// 多页 TIFF 只读取第一页的尺寸；PDF 没有像素尺寸
+func Inspect(data []byte) (ImageInfo, error) {
+	info := ImageInfo{Format: DetectFormat(data)}
+
+	var err error
+	switch info.Format {
+	case FormatPNG:
+		info.Width, info.Height, err = pngSize(data)
+	case FormatJPEG:
+		info.Width, info.Height, err = jpegSize(data)
+	case FormatTIFF:
+		info.Width, info.Height, err = tiffSize(data)
+	case FormatBMP:
+		info.Width, info.Height, err = bmpSize(data)
+	case FormatWebP:
+		info.Width, info.Height, err = webpSize(data)
+	case FormatGIF:
+		info.Width, info.Height, err = gifSize(data)
+	case FormatPDF:
+		return info, nil
+	default:
+		return info, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return info, err
+	}
+	if info.Width <= 0 || info.Height <= 0 {
+		return info, fmt.Errorf("%w: invalid dimensions %dx%d", ErrMalformedHeader, info.Width, info.Height)
+	}
+
+	return info, nil
+}
+
+// pngSize 读取 IHDR 中的尺寸
+func pngSize(data []byte) (int, int, error) {
+	if len(data) < 24 || !bytes.Equal(data[12:16], []byte("IHDR")) {
+		return 0, 0, fmt.Errorf("%w: missing PNG IHDR chunk", ErrMalformedHeader)
+	}
+	return int(binary.BigEndian.Uint32(data[16:20])), int(binary.BigEndian.Uint32(data[20:24])), nil
+}
+
+// jpegSize 扫描标记段，读取 SOF 段中的尺寸
+func jpegSize(data []byte) (int, int, error) {
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 0, 0, fmt.Errorf("%w: invalid JPEG marker at offset %d", ErrMalformedHeader, offset)
+		}
+
+		marker := data[offset+1]
+		switch {
+		case marker == 0xFF:
+			// 填充字节
+			offset++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// 无长度的独立标记
+			offset += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// 扫描数据或图像结束前未找到 SOF
+			return 0, 0, fmt.Errorf("%w: JPEG SOF marker not found", ErrMalformedHeader)
+		}
+
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 {
+			return 0, 0, fmt.Errorf("%w: invalid JPEG segment length", ErrMalformedHeader)
+		}
+
+		// SOF0-SOF15，排除 DHT (C4)、JPG (C8) 和 DAC (CC)
+		if marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC {
+			if offset+9 > len(data) {
+				break
+			}
+			height := int(binary.BigEndian.Uint16(data[offset+5:]))
+			width := int(binary.BigEndian.Uint16(data[offset+7:]))
+			return width, height, nil
+		}
+
+		offset += 2 + length
+	}
+
+	return 0, 0, fmt.Errorf("%w: truncated JPEG header", ErrMalformedHeader)
+}
+
+// tiffSize 读取第一个 IFD 中的 ImageWidth/ImageLength
+func tiffSize(data []byte) (int, int, error) {
+	if len(data) < 8 {
+		return 0, 0, fmt.Errorf("%w: truncated TIFF header", ErrMalformedHeader)
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	entries, _, err := readIFD(data, order, order.Uint32(data[4:8]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrMalformedHeader, err)
+	}
+
+	var size [2]int
+	for i, tag := range []uint16{tagImageWidth, tagImageLength} {
+		index := findEntry(entries, tag)
+		if index < 0 {
+			return 0, 0, fmt.Errorf("%w: missing TIFF tag %d", ErrMalformedHeader, tag)
+		}
+		values := entryValues(entries[index], order)
+		if len(values) == 0 {
+			return 0, 0, fmt.Errorf("%w: invalid TIFF tag %d", ErrMalformedHeader, tag)
+		}
+		size[i] = int(values[0])
+	}
+
+	return size[0], size[1], nil
+}
+
+// bmpSize 读取 DIB 头中的尺寸 (高度为负表示自上而下存储)
+func bmpSize(data []byte) (int, int, error) {
+	if len(data) < 26 {
+		return 0, 0, fmt.Errorf("%w: truncated BMP header", ErrMalformedHeader)
+	}
+
+	// BITMAPCOREHEADER 使用 16 位尺寸
+	if binary.LittleEndian.Uint32(data[14:18]) == 12 {
+		return int(binary.LittleEndian.Uint16(data[18:20])), int(binary.LittleEndian.Uint16(data[20:22])), nil
+	}
+
+	width := int(int32(binary.LittleEndian.Uint32(data[18:22])))
+	height := int(int32(binary.LittleEndian.Uint32(data[22:26])))
+	if height < 0 {
+		height = -height
+	}
+	return width, height, nil
+}
+
+// webpSize 读取 VP8/VP8L/VP8X 块中的尺寸
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, fmt.Errorf("%w: truncated WebP header", ErrMalformedHeader)
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// 有损: 3 字节帧标记 + 起始码 9d 01 2a + 14 位宽高
+		if !bytes.Equal(data[23:26], []byte{0x9D, 0x01, 0x2A}) {
+			return 0, 0, fmt.Errorf("%w: invalid VP8 start code", ErrMalformedHeader)
+		}
+		return int(binary.LittleEndian.Uint16(data[26:28]) & 0x3FFF), int(binary.LittleEndian.Uint16(data[28:30]) & 0x3FFF), nil
+	case "VP8L":
+		// 无损: 签名 0x2f + 14 位 (宽-1) + 14 位 (高-1)
+		if data[20] != 0x2F {
+			return 0, 0, fmt.Errorf("%w: invalid VP8L signature", ErrMalformedHeader)
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		return int(bits&0x3FFF) + 1, int((bits>>14)&0x3FFF) + 1, nil
+	case "VP8X":
+		// 扩展格式: 24 位 (画布宽-1) 和 (画布高-1)
+		width := int(uint32(data[24]) | uint32(data[25])<<8 | uint32(data[26])<<16)
+		height := int(uint32(data[27]) | uint32(data[28])<<8 | uint32(data[29])<<16)
+		return width + 1, height + 1, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: unknown WebP chunk %q", ErrMalformedHeader, data[12:16])
+	}
+}
+
+// gifSize 读取逻辑屏幕描述符中的尺寸
+func gifSize(data []byte) (int, int, error) {
+	if len(data) < 10 {
+		return 0, 0, fmt.Errorf("%w: truncated GIF header", ErrMalformedHeader)
+	}
+	return int(binary.LittleEndian.Uint16(data[6:8])), int(binary.LittleEndian.Uint16(data[8:10])), nil
+}
\ No newline at end of file
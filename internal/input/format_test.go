@@ -0,0 +1,158 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeImage(t *testing.T, format Format, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, nil)
+	case FormatGIF:
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func buildBMP(width, height int32) []byte {
+	data := make([]byte, 54)
+	copy(data, "BM")
+	binary.LittleEndian.PutUint32(data[14:], 40)
+	binary.LittleEndian.PutUint32(data[18:], uint32(width))
+	binary.LittleEndian.PutUint32(data[22:], uint32(height))
+	return data
+}
+
+func buildWebP(chunk string, payload []byte) []byte {
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"+chunk+"\x00\x00\x00\x00"), payload...)
+	for len(data) < 30 {
+		data = append(data, 0)
+	}
+	return data
+}
+
+func TestInspect(t *testing.T) {
+	// VP8L: 宽 640、高 480 (存储为减 1 后的 14 位值)
+	vp8l := make([]byte, 5)
+	vp8l[0] = 0x2F
+	binary.LittleEndian.PutUint32(vp8l[1:], uint32(639)|uint32(479)<<14)
+
+	// VP8X: 24 位画布尺寸 (减 1)
+	vp8x := make([]byte, 10)
+	vp8x[4], vp8x[5] = 0x1F, 0x03 // 800 - 1
+	vp8x[7], vp8x[8] = 0x57, 0x02 // 600 - 1
+
+	// VP8: 帧标记 + 起始码 + 14 位宽高
+	vp8 := []byte{0, 0, 0, 0x9D, 0x01, 0x2A, 0x40, 0x01, 0xF0, 0x00}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format Format
+		width  int
+		height int
+	}{
+		{"png", encodeImage(t, FormatPNG, 320, 200), FormatPNG, 320, 200},
+		{"jpeg", encodeImage(t, FormatJPEG, 64, 48), FormatJPEG, 64, 48},
+		{"gif", encodeImage(t, FormatGIF, 10, 20), FormatGIF, 10, 20},
+		{"tiff", buildTIFF(binary.BigEndian, [][]byte{{1, 2, 3}}), FormatTIFF, 3, 1},
+		{"bmp", buildBMP(100, -50), FormatBMP, 100, 50},
+		{"webp-vp8l", buildWebP("VP8L", vp8l), FormatWebP, 640, 480},
+		{"webp-vp8x", buildWebP("VP8X", vp8x), FormatWebP, 800, 600},
+		{"webp-vp8", buildWebP("VP8 ", vp8), FormatWebP, 320, 240},
+		{"pdf", []byte("%PDF-1.7\n"), FormatPDF, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Inspect(tt.data)
+			if err != nil {
+				t.Fatalf("Failed to inspect: %v", err)
+			}
+			if info.Format != tt.format || info.Width != tt.width || info.Height != tt.height {
+				t.Errorf("Expected %s %dx%d, got %s %dx%d",
+					tt.format, tt.width, tt.height, info.Format, info.Width, info.Height)
+			}
+		})
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	if _, err := Inspect([]byte("hello world")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+
+	// 截断的 PNG 和 JPEG
+	truncated := [][]byte{
+		encodeImage(t, FormatPNG, 8, 8)[:16],
+		encodeImage(t, FormatJPEG, 8, 8)[:20],
+		buildBMP(0, 10),
+	}
+	for _, data := range truncated {
+		if _, err := Inspect(data); !errors.Is(err, ErrMalformedHeader) {
+			t.Errorf("Expected ErrMalformedHeader for %s, got %v", DetectFormat(data), err)
+		}
+	}
+}
+
+func TestImageInfo_ExceedsPixels(t *testing.T) {
+	// IHDR 声明 0xFFFFFFFF x 0xFFFFFFFF，像素数超出 int64 范围
+	huge := encodeImage(t, FormatPNG, 1, 1)
+	binary.BigEndian.PutUint32(huge[16:], 0xFFFFFFFF)
+	binary.BigEndian.PutUint32(huge[20:], 0xFFFFFFFF)
+
+	info, err := Inspect(huge)
+	if err != nil {
+		t.Fatalf("Failed to inspect: %v", err)
+	}
+	if !info.ExceedsPixels(100000000) {
+		t.Errorf("Expected %dx%d to exceed pixel limit", info.Width, info.Height)
+	}
+
+	tests := []struct {
+		width, height int
+		maxPixels     int64
+		exceeds       bool
+	}{
+		{100, 100, 10000, false},
+		{100, 101, 10000, true},
+		{10001, 1, 10000, true},
+		{0, 0, 10000, false},
+	}
+	for _, tt := range tests {
+		info := ImageInfo{Format: FormatPNG, Width: tt.width, Height: tt.height}
+		if got := info.ExceedsPixels(tt.maxPixels); got != tt.exceeds {
+			t.Errorf("ExceedsPixels(%d) for %dx%d = %v, expected %v", tt.maxPixels, tt.width, tt.height, got, tt.exceeds)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, expected := range map[string]Format{"JPG": FormatJPEG, "tif": FormatTIFF, " webp ": FormatWebP} {
+		format, err := ParseFormat(name)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %s, %v; expected %s", name, format, err, expected)
+		}
+	}
+
+	if _, err := ParseFormat("svg"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
\ No newline at end of file
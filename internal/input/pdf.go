@@ -0,0 +1,195 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pdfMagic PDF 文件头
+var pdfMagic = []byte("%PDF-")
+
+// ErrPDFLimit PDF 超出页数限制，或页面按渲染分辨率换算后超出像素数限制
+var ErrPDFLimit = errors.New("PDF exceeds rasterization limits")
+
+// pdfinfo 输出中的页数和页面尺寸 (单位为点，1/72 英寸)
+var (
+	pdfPagesLine    = regexp.MustCompile(`(?m)^Pages:\s+(\d+)`)
+	pdfPageSizeLine = regexp.MustCompile(`(?m)^Page\s+(\d+)\s+size:\s+([\d.]+) x ([\d.]+) pts`)
+)
+
+// IsPDF 判断数据是否为 PDF 文档
+func IsPDF(data []byte) bool {
+	return bytes.HasPrefix(data, pdfMagic)
+}
+
+// PDFRasterizerOptions PDF 栅格化器配置
+type PDFRasterizerOptions struct {
+	Renderer  string // 栅格化命令 (默认 pdftoppm)
+	Info      string // 读取页数和页面尺寸的命令 (默认 pdfinfo)
+	DPI       int    // 渲染分辨率 (默认 300)
+	MaxPages  int    // 最大页数 (0 表示不限制)
+	MaxPixels int64  // 每页渲染后的最大像素数 (0 表示不限制)
+}
+
+// PDFRasterizer PDF 栅格化器 (调用 poppler 的 pdftoppm 渲染每一页)
+// 渲染前通过 pdfinfo 读取页数和页面尺寸，超出限制的文档不会被渲染
+type PDFRasterizer struct {
+	command   string
+	info      string
+	dpi       int
+	maxPages  int
+	maxPixels int64
+}
+
+// NewPDFRasterizer 创建 PDF 栅格化器
+func NewPDFRasterizer(opts PDFRasterizerOptions) *PDFRasterizer {
+	if opts.Renderer == "" {
+		opts.Renderer = "pdftoppm"
+	}
+	if opts.Info == "" {
+		opts.Info = "pdfinfo"
+	}
+	if opts.DPI <= 0 {
+		opts.DPI = 300
+	}
+	return &PDFRasterizer{
+		command:   opts.Renderer,
+		info:      opts.Info,
+		dpi:       opts.DPI,
+		maxPages:  opts.MaxPages,
+		maxPixels: opts.MaxPixels,
+	}
+}
+
+// DPI 返回渲染分辨率
+func (r *PDFRasterizer) DPI() int {
+	return r.dpi
+}
+
+// Rasterize 将 PDF 的每一页渲染为 PNG 图像 (按页码顺序)
+// 页数超过 maxPages，或任一页按 dpi 渲染后超过 maxPixels 时返回 ErrPDFLimit
+func (r *PDFRasterizer) Rasterize(ctx context.Context, data []byte) ([][]byte, error) {
+	if !IsPDF(data) {
+		return nil, fmt.Errorf("data is not a PDF document")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mcp-ocr-pdf-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	if err := os.WriteFile(inputPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write temp PDF: %w", err)
+	}
+
+	// 渲染前检查页数和页面尺寸，避免渲染出超大图像
+	if err := r.checkLimits(ctx, inputPath); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-r", strconv.Itoa(r.dpi), "-png"}
+	if r.maxPages > 0 {
+		args = append(args, "-l", strconv.Itoa(r.maxPages))
+	}
+	args = append(args, inputPath, filepath.Join(tmpDir, "page"))
+
+	cmd := exec.CommandContext(ctx, r.command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", r.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// pdftoppm 输出 page-1.png 或 page-01.png (按总页数补零)
+	files, err := filepath.Glob(filepath.Join(tmpDir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("PDF contains no pages")
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return pageNumber(files[i]) < pageNumber(files[j])
+	})
+
+	pages := make([][]byte, 0, len(files))
+	for _, file := range files {
+		page, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rendered page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// checkLimits 通过 pdfinfo 读取页数和各页尺寸，检查页数和渲染后的像素数
+func (r *PDFRasterizer) checkLimits(ctx context.Context, inputPath string) error {
+	// pdfinfo 会将 -l 截断到文档的实际页数
+	lastPage := math.MaxInt32
+	if r.maxPages > 0 {
+		lastPage = r.maxPages
+	}
+
+	cmd := exec.CommandContext(ctx, r.info, "-f", "1", "-l", strconv.Itoa(lastPage), inputPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s failed: %w: %s", r.info, err, strings.TrimSpace(stderr.String()))
+	}
+
+	match := pdfPagesLine.FindSubmatch(output)
+	if match == nil {
+		return fmt.Errorf("%s output does not contain page count", r.info)
+	}
+	pages, _ := strconv.Atoi(string(match[1]))
+	if r.maxPages > 0 && pages > r.maxPages {
+		return fmt.Errorf("%w: %d pages, at most %d allowed", ErrPDFLimit, pages, r.maxPages)
+	}
+
+	if r.maxPixels <= 0 {
+		return nil
+	}
+
+	for _, size := range pdfPageSizeLine.FindAllSubmatch(output, -1) {
+		page, _ := strconv.Atoi(string(size[1]))
+		width, _ := strconv.ParseFloat(string(size[2]), 64)
+		height, _ := strconv.ParseFloat(string(size[3]), 64)
+
+		pixels := math.Ceil(width/72*float64(r.dpi)) * math.Ceil(height/72*float64(r.dpi))
+		if pixels > float64(r.maxPixels) {
+			return fmt.Errorf("%w: page %d renders to %.0f pixels at %d dpi, at most %d allowed",
+				ErrPDFLimit, page, pixels, r.dpi, r.maxPixels)
+		}
+	}
+
+	return nil
+}
+
+// pageNumber 从 pdftoppm 输出文件名中解析页码
+func pageNumber(path string) int {
+	name := strings.TrimSuffix(filepath.Base(path), ".png")
+	n, _ := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	return n
+}
\ No newline at end of file
@@ -0,0 +1,139 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestIsPDF(t *testing.T) {
+	if !IsPDF([]byte("%PDF-1.7\n...")) {
+		t.Error("Expected PDF to be detected")
+	}
+
+	if IsPDF([]byte("\x89PNG\r\n\x1a\n")) {
+		t.Error("Expected PNG not to be detected as PDF")
+	}
+}
+
+// writeFakePDFInfo 模拟 pdfinfo: 输出总页数和每页尺寸 (单位为点)
+func writeFakePDFInfo(t *testing.T, pages int, width, height float64) string {
+	t.Helper()
+
+	content := fmt.Sprintf("#!/bin/sh\necho 'Pages:          %d'\n", pages)
+	for i := 1; i <= pages; i++ {
+		content += fmt.Sprintf("echo 'Page %4d size: %g x %g pts'\n", i, width, height)
+	}
+
+	script := filepath.Join(t.TempDir(), "fake-pdfinfo")
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake pdfinfo: %v", err)
+	}
+	return script
+}
+
+func TestPDFRasterizer_PageOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script renderer not supported on windows")
+	}
+
+	// 模拟 pdftoppm: 按补零后的文件名输出 3 页，内容为页码
+	script := filepath.Join(t.TempDir(), "fake-pdftoppm")
+	content := "#!/bin/sh\nfor p in \"$@\"; do prefix=\"$p\"; done\n" +
+		"printf 1 > \"$prefix-01.png\"\nprintf 2 > \"$prefix-02.png\"\nprintf 10 > \"$prefix-10.png\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake renderer: %v", err)
+	}
+
+	rasterizer := NewPDFRasterizer(PDFRasterizerOptions{
+		Renderer: script,
+		Info:     writeFakePDFInfo(t, 3, 612, 792),
+		DPI:      150,
+	})
+	pages, err := rasterizer.Rasterize(context.Background(), []byte("%PDF-1.4\n"))
+	if err != nil {
+		t.Fatalf("Failed to rasterize: %v", err)
+	}
+
+	expected := []string{"1", "2", "10"}
+	if len(pages) != len(expected) {
+		t.Fatalf("Expected %d pages, got %d", len(expected), len(pages))
+	}
+	for i, page := range pages {
+		if string(page) != expected[i] {
+			t.Errorf("Page %d: expected %s, got %s", i, expected[i], page)
+		}
+	}
+}
+
+func TestPDFRasterizer_Limits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script renderer not supported on windows")
+	}
+
+	// 渲染命令不应被调用
+	renderer := filepath.Join(t.TempDir(), "fake-pdftoppm")
+	if err := os.WriteFile(renderer, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake renderer: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   PDFRasterizerOptions
+		pages  int
+		width  float64
+		height float64
+	}{
+		{
+			name:   "too many pages",
+			opts:   PDFRasterizerOptions{MaxPages: 2},
+			pages:  3,
+			width:  612,
+			height: 792,
+		},
+		{
+			// 100 x 100 英寸的页面在 300 DPI 下为 9 亿像素
+			name:   "page too large",
+			opts:   PDFRasterizerOptions{MaxPixels: 100000000},
+			pages:  1,
+			width:  7200,
+			height: 7200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Renderer = renderer
+			opts.Info = writeFakePDFInfo(t, tt.pages, tt.width, tt.height)
+
+			_, err := NewPDFRasterizer(opts).Rasterize(context.Background(), []byte("%PDF-1.4\n"))
+			if !errors.Is(err, ErrPDFLimit) {
+				t.Errorf("Expected ErrPDFLimit, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPDFRasterizer_Errors(t *testing.T) {
+	rasterizer := NewPDFRasterizer(PDFRasterizerOptions{
+		Renderer: "/nonexistent/pdftoppm",
+		Info:     "/nonexistent/pdfinfo",
+	})
+
+	if rasterizer.DPI() != 300 {
+		t.Errorf("Expected default DPI 300, got %d", rasterizer.DPI())
+	}
+
+	if _, err := rasterizer.Rasterize(context.Background(), []byte("not a pdf")); err == nil {
+		t.Error("Expected error for non-PDF data")
+	}
+
+	if _, err := rasterizer.Rasterize(context.Background(), []byte("%PDF-1.4\n")); err == nil {
+		t.Error("Expected error for missing renderer")
+	}
+}
\ No newline at end of file
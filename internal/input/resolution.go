@@ -0,0 +1,118 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// TIFF 分辨率标签
+const (
+	tagXResolution    = 282
+	tagResolutionUnit = 296
+
+	tiffTypeRational = 5
+)
+
+// Resolution 读取文件头中声明的水平分辨率 (DPI)
+// 支持 PNG (pHYs)、JPEG (JFIF) 和 TIFF (XResolution)，未声明或无法解析时返回 false
+func Resolution(data []byte) (float64, bool) {
+	var dpi float64
+	switch DetectFormat(data) {
+	case FormatPNG:
+		dpi = pngResolution(data)
+	case FormatJPEG:
+		dpi = jpegResolution(data)
+	case FormatTIFF:
+		dpi = tiffResolution(data)
+	}
+
+	return dpi, dpi > 0
+}
+
+// pngResolution 读取 pHYs 块 (单位为米时换算为 DPI)
+func pngResolution(data []byte) float64 {
+	offset := 8
+	for offset+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunk := string(data[offset+4 : offset+8])
+		if chunk == "IDAT" || length < 0 || offset+8+length > len(data) {
+			// pHYs 必须位于 IDAT 之前
+			return 0
+		}
+
+		if chunk == "pHYs" && length >= 9 {
+			body := data[offset+8:]
+			if body[8] != 1 {
+				// 单位未知，仅表示像素宽高比
+				return 0
+			}
+			return float64(binary.BigEndian.Uint32(body[0:4])) * 0.0254
+		}
+
+		offset += 12 + length
+	}
+
+	return 0
+}
+
+// jpegResolution 读取 JFIF APP0 段中的像素密度
+func jpegResolution(data []byte) float64 {
+	if len(data) < 20 || data[2] != 0xFF || data[3] != 0xE0 || !bytes.Equal(data[6:11], []byte("JFIF\x00")) {
+		return 0
+	}
+
+	density := float64(binary.BigEndian.Uint16(data[14:16]))
+	switch data[13] {
+	case 1: // 每英寸
+		return density
+	case 2: // 每厘米
+		return density * 2.54
+	default:
+		return 0
+	}
+}
+
+// tiffResolution 读取第一个 IFD 中的 XResolution 和 ResolutionUnit
+func tiffResolution(data []byte) float64 {
+	if len(data) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	entries, _, err := readIFD(data, order, order.Uint32(data[4:8]))
+	if err != nil {
+		return 0
+	}
+
+	index := findEntry(entries, tagXResolution)
+	if index < 0 || entries[index].typ != tiffTypeRational || len(entries[index].value) < 8 {
+		return 0
+	}
+	value := entries[index].value
+	numerator, denominator := order.Uint32(value[0:4]), order.Uint32(value[4:8])
+	if denominator == 0 {
+		return 0
+	}
+	resolution := float64(numerator) / float64(denominator)
+
+	// ResolutionUnit: 1 无单位，2 英寸 (默认)，3 厘米
+	unit := uint32(2)
+	if index := findEntry(entries, tagResolutionUnit); index >= 0 {
+		if values := entryValues(entries[index], order); len(values) > 0 {
+			unit = values[0]
+		}
+	}
+
+	switch unit {
+	case 2:
+		return resolution
+	case 3:
+		return resolution * 2.54
+	default:
+		return 0
+	}
+}
\ No newline at end of file
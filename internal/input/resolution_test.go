@@ -0,0 +1,111 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// withPNGChunk 在 IHDR 之后插入一个 PNG 块 (不校验 CRC)
+func withPNGChunk(data []byte, chunk string, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(data[:33]) // 签名 + IHDR
+	binary.Write(&buf, binary.BigEndian, uint32(len(body)))
+	buf.WriteString(chunk)
+	buf.Write(body)
+	buf.Write([]byte{0, 0, 0, 0})
+	buf.Write(data[33:])
+	return buf.Bytes()
+}
+
+// buildJFIF 构造只包含 SOI 和 JFIF APP0 段的 JPEG 头
+func buildJFIF(units byte, density uint16) []byte {
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}
+	data = append(data, "JFIF\x00\x01\x01"...)
+	data = append(data, units, byte(density>>8), byte(density), byte(density>>8), byte(density), 0, 0)
+	return data
+}
+
+// buildTIFFResolution 构造只包含分辨率标签的 TIFF 头
+func buildTIFFResolution(order binary.ByteOrder, numerator, denominator uint32, unit uint16) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II*\x00")
+	} else {
+		buf.WriteString("MM\x00*")
+	}
+	writeUint32(&buf, order, 8)
+
+	// 2 个条目，RATIONAL 值放在 IFD 之后
+	writeUint16(&buf, order, 2)
+	writeUint16(&buf, order, tagXResolution)
+	writeUint16(&buf, order, tiffTypeRational)
+	writeUint32(&buf, order, 1)
+	writeUint32(&buf, order, 8+2+2*12+4)
+	writeUint16(&buf, order, tagResolutionUnit)
+	writeUint16(&buf, order, tiffTypeShort)
+	writeUint32(&buf, order, 1)
+	writeUint16(&buf, order, unit)
+	writeUint16(&buf, order, 0)
+	writeUint32(&buf, order, 0)
+
+	writeUint32(&buf, order, numerator)
+	writeUint32(&buf, order, denominator)
+	return buf.Bytes()
+}
+
+func TestResolution(t *testing.T) {
+	plainPNG := encodeImage(t, FormatPNG, 4, 4)
+
+	// 300 DPI ≈ 11811 像素/米
+	phys := make([]byte, 9)
+	binary.BigEndian.PutUint32(phys[0:], 11811)
+	binary.BigEndian.PutUint32(phys[4:], 11811)
+	phys[8] = 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want float64
+	}{
+		{"png pHYs", withPNGChunk(plainPNG, "pHYs", phys), 300},
+		{"jpeg dpi", buildJFIF(1, 200), 200},
+		{"jpeg dpcm", buildJFIF(2, 118), 299.72},
+		{"tiff inch", buildTIFFResolution(binary.LittleEndian, 600, 2, 2), 300},
+		{"tiff cm", buildTIFFResolution(binary.BigEndian, 118, 1, 3), 299.72},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpi, ok := Resolution(tt.data)
+			if !ok {
+				t.Fatal("Expected resolution to be found")
+			}
+			if math.Abs(dpi-tt.want) > 0.5 {
+				t.Errorf("Expected %.2f DPI, got %.2f", tt.want, dpi)
+			}
+		})
+	}
+}
+
+func TestResolution_Missing(t *testing.T) {
+	// 单位未知的 pHYs 只表示宽高比
+	aspect := make([]byte, 9)
+	binary.BigEndian.PutUint32(aspect[0:], 1)
+	binary.BigEndian.PutUint32(aspect[4:], 1)
+
+	for name, data := range map[string][]byte{
+		"png without pHYs": encodeImage(t, FormatPNG, 4, 4),
+		"png aspect only":  withPNGChunk(encodeImage(t, FormatPNG, 4, 4), "pHYs", aspect),
+		"jpeg aspect only": buildJFIF(0, 1),
+		"tiff no unit":     buildTIFFResolution(binary.LittleEndian, 300, 1, 1),
+		"tiff zero":        buildTIFFResolution(binary.LittleEndian, 300, 0, 2),
+		"gif":              encodeImage(t, FormatGIF, 4, 4),
+		"empty":            nil,
+	} {
+		if dpi, ok := Resolution(data); ok {
+			t.Errorf("%s: expected no resolution, got %.2f", name, dpi)
+		}
+	}
+}
\ No newline at end of file
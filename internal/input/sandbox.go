@@ -0,0 +1,115 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathNotAllowed 路径不在允许的根目录内
+var ErrPathNotAllowed = errors.New("path not allowed")
+
+// PathSandbox 将文件访问限制在允许的根目录内 (解析符号链接后判断)
+type PathSandbox struct {
+	roots []string
+}
+
+// NewPathSandbox 创建路径沙箱，roots 为空表示不限制
+func NewPathSandbox(roots []string) (*PathSandbox, error) {
+	sandbox := &PathSandbox{}
+
+	for _, root := range roots {
+		resolved, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %q: %w", root, err)
+		}
+		resolved, err = filepath.EvalSymlinks(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %q: %w", root, err)
+		}
+		sandbox.roots = append(sandbox.roots, resolved)
+	}
+
+	return sandbox, nil
+}
+
+// Enabled 是否限制了根目录
+func (s *PathSandbox) Enabled() bool {
+	return s != nil && len(s.roots) > 0
+}
+
+// Roots 返回解析后的根目录
+func (s *PathSandbox) Roots() []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s.roots...)
+}
+
+// Resolve 解析待读取的路径 (包括符号链接)，返回实际路径
+// 路径在根目录外时返回 ErrPathNotAllowed，不存在时返回 os.ErrNotExist
+func (s *PathSandbox) Resolve(path string) (string, error) {
+	if !s.Enabled() {
+		return filepath.Clean(path), nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		// 根目录外的路径不暴露其是否存在
+		if !s.contains(absPath) {
+			return "", ErrPathNotAllowed
+		}
+		return "", err
+	}
+
+	if !s.contains(resolved) {
+		return "", ErrPathNotAllowed
+	}
+
+	return resolved, nil
+}
+
+// ResolveOutput 解析待写入的路径: 文件可以不存在，但所在目录 (解析符号链接后) 必须在根目录内
+func (s *PathSandbox) ResolveOutput(path string) (string, error) {
+	if !s.Enabled() {
+		return filepath.Clean(path), nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	// 已存在的文件 (可能是符号链接) 按读取路径处理
+	if _, err := os.Lstat(absPath); err == nil {
+		return s.Resolve(absPath)
+	}
+
+	dir, err := s.Resolve(filepath.Dir(absPath))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.Base(absPath)), nil
+}
+
+// contains 判断路径是否在某个根目录内
+func (s *PathSandbox) contains(path string) bool {
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
@@ -0,0 +1,104 @@
+package input
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathSandbox_Resolve(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	inside := filepath.Join(root, "scan.png")
+	secret := filepath.Join(outside, "secret.txt")
+	for _, path := range []string{inside, secret} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// 指向根目录外的符号链接
+	link := filepath.Join(root, "link.png")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	sandbox, err := NewPathSandbox([]string{root})
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+
+	resolved, err := sandbox.Resolve(inside)
+	if err != nil {
+		t.Errorf("Expected path inside root to be allowed: %v", err)
+	}
+	if filepath.Base(resolved) != "scan.png" {
+		t.Errorf("Unexpected resolved path: %s", resolved)
+	}
+
+	tests := []string{
+		secret,
+		link,
+		filepath.Join(root, "..", filepath.Base(outside), "secret.txt"),
+		filepath.Join(outside, "missing.png"),
+		"/etc/shadow",
+	}
+	for _, path := range tests {
+		if _, err := sandbox.Resolve(path); !errors.Is(err, ErrPathNotAllowed) {
+			t.Errorf("Expected ErrPathNotAllowed for %s, got %v", path, err)
+		}
+	}
+
+	if _, err := sandbox.Resolve(filepath.Join(root, "missing.png")); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got %v", err)
+	}
+}
+
+func TestPathSandbox_ResolveOutput(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	sandbox, err := NewPathSandbox([]string{root})
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+
+	if _, err := sandbox.ResolveOutput(filepath.Join(root, "out.pdf")); err != nil {
+		t.Errorf("Expected new file inside root to be allowed: %v", err)
+	}
+
+	if _, err := sandbox.ResolveOutput(filepath.Join(outside, "out.pdf")); !errors.Is(err, ErrPathNotAllowed) {
+		t.Errorf("Expected ErrPathNotAllowed, got %v", err)
+	}
+
+	// 通过符号链接目录写到根目录外
+	linkDir := filepath.Join(root, "escape")
+	if err := os.Symlink(outside, linkDir); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if _, err := sandbox.ResolveOutput(filepath.Join(linkDir, "out.pdf")); !errors.Is(err, ErrPathNotAllowed) {
+		t.Errorf("Expected ErrPathNotAllowed through symlinked dir, got %v", err)
+	}
+}
+
+func TestPathSandbox_Disabled(t *testing.T) {
+	sandbox, err := NewPathSandbox(nil)
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+
+	if sandbox.Enabled() {
+		t.Error("Expected sandbox without roots to be disabled")
+	}
+
+	resolved, err := sandbox.Resolve("/tmp/../etc/hosts")
+	if err != nil || resolved != "/etc/hosts" {
+		t.Errorf("Expected cleaned path, got %s, %v", resolved, err)
+	}
+
+	if _, err := NewPathSandbox([]string{"/nonexistent/root"}); err == nil {
+		t.Error("Expected error for missing root")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,294 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrTIFFLimit 多页 TIFF 超出页数或拆分后的总大小限制
+var ErrTIFFLimit = errors.New("TIFF exceeds split limits")
+
+// TIFF 标签
+const (
+	tagImageWidth      = 256
+	tagImageLength     = 257
+	tagStripOffsets    = 273
+	tagStripByteCounts = 279
+	tagTileOffsets     = 324
+	tagTileByteCounts  = 325
+	tagSubIFDs         = 330
+	tagExifIFD         = 34665
+	tagGPSIFD          = 34853
+	tagInteropIFD      = 40965
+
+	tiffTypeShort = 3
+	tiffTypeLong  = 4
+)
+
+// tiffTypeSizes TIFF 字段类型对应的字节数
+var tiffTypeSizes = map[uint16]uint32{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8,
+}
+
+// IsTIFF 判断数据是否为 TIFF 图像 (不支持 BigTIFF)
+func IsTIFF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*"))
+}
+
+// tiffEntry IFD 条目 (value 为按文件字节序编码的原始值)
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// SplitTIFF 将多页 TIFF 拆分为多个单页 TIFF (按 IFD 顺序)
+//
+// 每页复制其 IFD 条目和图像数据 (strip/tile) 并重写偏移量，像素数据保持原样不重新编码。
+// 子 IFD、EXIF 和 GPS 等指针标签会被丢弃。
+// maxPages 为最大页数，maxBytes 为拆分后各页的总大小上限 (均为 0 表示不限制)，
+// 超出时返回 ErrTIFFLimit。多个 IFD 可以指向同一段图像数据，总大小限制避免小文件被放大为大量副本。
+func SplitTIFF(data []byte, maxPages int, maxBytes int64) ([][]byte, error) {
+	if !IsTIFF(data) {
+		return nil, fmt.Errorf("data is not a TIFF image")
+	}
+	if len(data) < 8 {
+		return nil, fmt.Errorf("truncated TIFF header")
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	var pages [][]byte
+	var total int64
+	visited := make(map[uint32]bool)
+	offset := order.Uint32(data[4:8])
+
+	for offset != 0 {
+		if maxPages > 0 && len(pages) >= maxPages {
+			return nil, fmt.Errorf("%w: more than %d pages", ErrTIFFLimit, maxPages)
+		}
+		if visited[offset] {
+			return nil, fmt.Errorf("TIFF IFD chain contains a loop at offset %d", offset)
+		}
+		visited[offset] = true
+
+		entries, next, err := readIFD(data, order, offset)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", len(pages)+1, err)
+		}
+
+		// 本页可用的大小 (0 表示不限制)
+		budget := int64(0)
+		if maxBytes > 0 {
+			budget = maxBytes - total
+			if budget <= 0 {
+				return nil, fmt.Errorf("%w: split pages exceed %d bytes", ErrTIFFLimit, maxBytes)
+			}
+		}
+
+		page, err := writePage(data, order, entries, budget)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", len(pages)+1, err)
+		}
+
+		total += int64(len(page))
+		pages = append(pages, page)
+		offset = next
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("TIFF contains no pages")
+	}
+
+	return pages, nil
+}
+
+// readIFD 读取 offset 处的 IFD，返回条目和下一个 IFD 的偏移量
+func readIFD(data []byte, order binary.ByteOrder, offset uint32) ([]tiffEntry, uint32, error) {
+	if uint64(offset)+2 > uint64(len(data)) {
+		return nil, 0, fmt.Errorf("IFD offset %d out of range", offset)
+	}
+
+	n := uint32(order.Uint16(data[offset:]))
+	end := uint64(offset) + 2 + uint64(n)*12 + 4
+	if end > uint64(len(data)) {
+		return nil, 0, fmt.Errorf("truncated IFD at offset %d", offset)
+	}
+
+	entries := make([]tiffEntry, 0, n)
+	for i := uint32(0); i < n; i++ {
+		raw := data[offset+2+i*12 : offset+2+(i+1)*12]
+		entry := tiffEntry{
+			tag:   order.Uint16(raw[0:2]),
+			typ:   order.Uint16(raw[2:4]),
+			count: order.Uint32(raw[4:8]),
+		}
+
+		switch entry.tag {
+		case tagSubIFDs, tagExifIFD, tagGPSIFD, tagInteropIFD:
+			continue
+		}
+
+		typeSize, ok := tiffTypeSizes[entry.typ]
+		if !ok {
+			// 未知类型无法确定长度，忽略该条目
+			continue
+		}
+
+		size := uint64(typeSize) * uint64(entry.count)
+		if size <= 4 {
+			entry.value = append([]byte(nil), raw[8:8+size]...)
+		} else {
+			valueOffset := uint64(order.Uint32(raw[8:12]))
+			if valueOffset+size > uint64(len(data)) {
+				return nil, 0, fmt.Errorf("value of tag %d out of range", entry.tag)
+			}
+			entry.value = append([]byte(nil), data[valueOffset:valueOffset+size]...)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	next := order.Uint32(data[end-4:])
+	return entries, next, nil
+}
+
+// writePage 用单个 IFD 生成独立的 TIFF 文件
+// maxSize 为生成文件的大小上限 (0 表示不限制)，在复制图像数据前检查
+func writePage(data []byte, order binary.ByteOrder, entries []tiffEntry, maxSize int64) ([]byte, error) {
+	offsetsIdx, countsIdx := findEntry(entries, tagStripOffsets), findEntry(entries, tagStripByteCounts)
+	if offsetsIdx < 0 || countsIdx < 0 {
+		offsetsIdx, countsIdx = findEntry(entries, tagTileOffsets), findEntry(entries, tagTileByteCounts)
+	}
+	if offsetsIdx < 0 || countsIdx < 0 {
+		return nil, fmt.Errorf("missing strip or tile offsets")
+	}
+
+	offsets := entryValues(entries[offsetsIdx], order)
+	counts := entryValues(entries[countsIdx], order)
+	if len(offsets) == 0 || len(offsets) != len(counts) {
+		return nil, fmt.Errorf("strip offsets and byte counts mismatch")
+	}
+
+	// 偏移量统一改写为 LONG，便于容纳新文件中的位置
+	entries[offsetsIdx].typ = tiffTypeLong
+	entries[offsetsIdx].value = make([]byte, 4*len(offsets))
+
+	// 布局: 文件头 | IFD | 外部存储的条目值 | 图像数据
+	pos := uint32(8 + 2 + 12*len(entries) + 4)
+	valueOffsets := make([]uint32, len(entries))
+	for i, entry := range entries {
+		if len(entry.value) > 4 {
+			valueOffsets[i] = pos
+			pos += align(uint32(len(entry.value)))
+		}
+	}
+
+	size := uint64(pos)
+	for i := range offsets {
+		start, count := uint64(offsets[i]), uint64(counts[i])
+		if start+count > uint64(len(data)) {
+			return nil, fmt.Errorf("image data out of range")
+		}
+		size += count
+	}
+	if maxSize > 0 && size > uint64(maxSize) {
+		return nil, fmt.Errorf("%w: split pages exceed %d bytes", ErrTIFFLimit, maxSize)
+	}
+	if size > math.MaxUint32 {
+		return nil, fmt.Errorf("page exceeds 4GB")
+	}
+
+	var strips bytes.Buffer
+	strips.Grow(int(size - uint64(pos)))
+	for i := range offsets {
+		start, size := uint64(offsets[i]), uint64(counts[i])
+		order.PutUint32(entries[offsetsIdx].value[4*i:], pos+uint32(strips.Len()))
+		strips.Write(data[start : start+size])
+	}
+
+	var out bytes.Buffer
+	if order == binary.LittleEndian {
+		out.WriteString("II*\x00")
+	} else {
+		out.WriteString("MM\x00*")
+	}
+	writeUint32(&out, order, 8)
+
+	writeUint16(&out, order, uint16(len(entries)))
+	for i, entry := range entries {
+		writeUint16(&out, order, entry.tag)
+		writeUint16(&out, order, entry.typ)
+		writeUint32(&out, order, entry.count)
+		if len(entry.value) > 4 {
+			writeUint32(&out, order, valueOffsets[i])
+		} else {
+			var inline [4]byte
+			copy(inline[:], entry.value)
+			out.Write(inline[:])
+		}
+	}
+	writeUint32(&out, order, 0)
+
+	for _, entry := range entries {
+		if len(entry.value) > 4 {
+			out.Write(entry.value)
+			if len(entry.value)%2 == 1 {
+				out.WriteByte(0)
+			}
+		}
+	}
+
+	out.Write(strips.Bytes())
+	return out.Bytes(), nil
+}
+
+// findEntry 查找标签对应的条目下标
+func findEntry(entries []tiffEntry, tag uint16) int {
+	for i, entry := range entries {
+		if entry.tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// entryValues 解码 SHORT/LONG 类型条目的数值
+func entryValues(entry tiffEntry, order binary.ByteOrder) []uint32 {
+	values := make([]uint32, 0, entry.count)
+	for i := uint32(0); i < entry.count; i++ {
+		switch entry.typ {
+		case tiffTypeShort:
+			values = append(values, uint32(order.Uint16(entry.value[2*i:])))
+		case tiffTypeLong:
+			values = append(values, order.Uint32(entry.value[4*i:]))
+		}
+	}
+	return values
+}
+
+// align 按字 (2 字节) 边界对齐
+func align(n uint32) uint32 {
+	return n + n%2
+}
+
+// writeUint16 按字节序写入 16 位整数
+func writeUint16(buf *bytes.Buffer, order binary.ByteOrder, v uint16) {
+	var b [2]byte
+	order.PutUint16(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeUint32 按字节序写入 32 位整数
+func writeUint32(buf *bytes.Buffer, order binary.ByteOrder, v uint32) {
+	var b [4]byte
+	order.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// buildTIFF 构造未压缩 8 位灰度的多页 TIFF，每页为单行像素 (按页顺序链接 IFD)
+func buildTIFF(order binary.ByteOrder, pixels [][]byte) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II*\x00")
+	} else {
+		buf.WriteString("MM\x00*")
+	}
+	writeUint32(&buf, order, 8)
+
+	const entryCount = 9
+	ifdSize := uint32(2 + entryCount*12 + 4)
+
+	// 布局: 所有 IFD 依次排列，像素数据放在末尾
+	dataStart := 8 + ifdSize*uint32(len(pixels))
+	dataOffset := dataStart
+
+	for i, row := range pixels {
+		writeUint16(&buf, order, entryCount)
+		entry := func(tag, typ uint16, value uint32) {
+			writeUint16(&buf, order, tag)
+			writeUint16(&buf, order, typ)
+			writeUint32(&buf, order, 1)
+			if typ == tiffTypeShort {
+				writeUint16(&buf, order, uint16(value))
+				writeUint16(&buf, order, 0)
+			} else {
+				writeUint32(&buf, order, value)
+			}
+		}
+		entry(256, tiffTypeShort, uint32(len(row)))      // ImageWidth
+		entry(257, tiffTypeShort, 1)                     // ImageLength
+		entry(258, tiffTypeShort, 8)                     // BitsPerSample
+		entry(259, tiffTypeShort, 1)                     // Compression
+		entry(262, tiffTypeShort, 1)                     // PhotometricInterpretation
+		entry(tagStripOffsets, tiffTypeLong, dataOffset) // StripOffsets
+		entry(277, tiffTypeShort, 1)                     // SamplesPerPixel
+		entry(278, tiffTypeShort, 1)                     // RowsPerStrip
+		entry(tagStripByteCounts, tiffTypeLong, uint32(len(row)))
+
+		next := uint32(0)
+		if i < len(pixels)-1 {
+			next = 8 + ifdSize*uint32(i+1)
+		}
+		writeUint32(&buf, order, next)
+		dataOffset += uint32(len(row))
+	}
+
+	for _, row := range pixels {
+		buf.Write(row)
+	}
+
+	return buf.Bytes()
+}
+
+// stripData 读取单页 TIFF 的图像数据
+func stripData(t *testing.T, page []byte, order binary.ByteOrder) []byte {
+	t.Helper()
+
+	entries, next, err := readIFD(page, order, order.Uint32(page[4:8]))
+	if err != nil {
+		t.Fatalf("Failed to read page IFD: %v", err)
+	}
+	if next != 0 {
+		t.Errorf("Expected single-page TIFF, next IFD at %d", next)
+	}
+
+	offsets := entryValues(entries[findEntry(entries, tagStripOffsets)], order)
+	counts := entryValues(entries[findEntry(entries, tagStripByteCounts)], order)
+	return page[offsets[0] : offsets[0]+counts[0]]
+}
+
+func TestSplitTIFF(t *testing.T) {
+	pixels := [][]byte{
+		{0x00, 0x10, 0x20},
+		{0xff, 0xee},
+		{0x01, 0x02, 0x03, 0x04},
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data := buildTIFF(order, pixels)
+		if !IsTIFF(data) {
+			t.Fatalf("Expected %s TIFF to be detected", order)
+		}
+
+		pages, err := SplitTIFF(data, 0, 0)
+		if err != nil {
+			t.Fatalf("Failed to split %s TIFF: %v", order, err)
+		}
+
+		if len(pages) != len(pixels) {
+			t.Fatalf("Expected %d pages, got %d", len(pixels), len(pages))
+		}
+
+		for i, page := range pages {
+			if !IsTIFF(page) {
+				t.Errorf("Page %d is not a TIFF", i+1)
+			}
+			if got := stripData(t, page, order); !bytes.Equal(got, pixels[i]) {
+				t.Errorf("Page %d: expected pixels %v, got %v", i+1, pixels[i], got)
+			}
+		}
+	}
+}
+
+func TestSplitTIFF_Errors(t *testing.T) {
+	if _, err := SplitTIFF([]byte("\x89PNG\r\n\x1a\n"), 0, 0); err == nil {
+		t.Error("Expected error for non-TIFF data")
+	}
+
+	// IFD 指向自身形成循环
+	data := buildTIFF(binary.LittleEndian, [][]byte{{0x00}})
+	ifdSize := 2 + 9*12
+	binary.LittleEndian.PutUint32(data[8+ifdSize:], 8)
+	if _, err := SplitTIFF(data, 0, 0); err == nil {
+		t.Error("Expected error for IFD loop")
+	}
+
+	// 截断的图像数据
+	data = buildTIFF(binary.LittleEndian, [][]byte{{0x00, 0x01, 0x02}})
+	if _, err := SplitTIFF(data[:len(data)-1], 0, 0); err == nil {
+		t.Error("Expected error for truncated image data")
+	}
+}
+
+func TestSplitTIFF_Limits(t *testing.T) {
+	data := buildTIFF(binary.LittleEndian, [][]byte{{0x00}, {0x01}, {0x02}})
+
+	if _, err := SplitTIFF(data, 2, 0); !errors.Is(err, ErrTIFFLimit) {
+		t.Errorf("Expected page limit error, got %v", err)
+	}
+	if pages, err := SplitTIFF(data, 3, 0); err != nil || len(pages) != 3 {
+		t.Errorf("Expected 3 pages within limit, got %d pages, err %v", len(pages), err)
+	}
+
+	// 所有页面指向同一段较大的图像数据，拆分后会被复制多次
+	row := bytes.Repeat([]byte{0x7f}, 4096)
+	data = buildTIFF(binary.LittleEndian, [][]byte{row, {0x00}, {0x00}, {0x00}})
+	ifdSize := 2 + 9*12 + 4
+	for i := 1; i < 4; i++ {
+		ifd := data[8+ifdSize*i:]
+		copy(ifd[2+5*12+8:], data[8+2+5*12+8:8+2+5*12+12]) // StripOffsets
+		copy(ifd[2+8*12+8:], data[8+2+8*12+8:8+2+8*12+12]) // StripByteCounts
+	}
+
+	pages, err := SplitTIFF(data, 0, 0)
+	if err != nil || len(pages) != 4 {
+		t.Fatalf("Expected 4 pages without limit, got %d pages, err %v", len(pages), err)
+	}
+	if _, err := SplitTIFF(data, 0, 3*4096); !errors.Is(err, ErrTIFFLimit) {
+		t.Errorf("Expected byte budget error, got %v", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status 任务/任务项状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// IsFinal 判断状态是否为终止状态
+func (s Status) IsFinal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// Item 任务项
+type Item struct {
+	Index      int         // 在任务中的序号 (从 0 开始)
+	Input      string      // 输入 (如图像路径)
+	Status     Status      // 状态
+	Result     interface{} // 处理结果 (完成时)
+	Err        error       // 错误 (失败时)
+	StartedAt  time.Time   // 开始时间
+	FinishedAt time.Time   // 结束时间
+}
+
+// Progress 任务进度
+type Progress struct {
+	Total     int
+	Pending   int
+	Running   int
+	Completed int
+	Failed    int
+	Cancelled int
+}
+
+// Done 已结束的任务项数量
+func (p Progress) Done() int {
+	return p.Completed + p.Failed + p.Cancelled
+}
+
+// Job 异步任务
+type Job struct {
+	ID        string
+	Owner     string // 提交任务的调用方 (未认证时为空)
+	CreatedAt time.Time
+
+	items     []*Item
+	status    Status
+	cancelled bool
+	endedAt   time.Time
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mu        sync.RWMutex
+}
+
+// newJob 创建任务
+func newJob(id, owner string, inputs []string) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	items := make([]*Item, len(inputs))
+	for i, input := range inputs {
+		items[i] = &Item{Index: i, Input: input, Status: StatusPending}
+	}
+
+	return &Job{
+		ID:        id,
+		Owner:     owner,
+		CreatedAt: time.Now(),
+		items:     items,
+		status:    StatusPending,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Status 获取任务状态
+func (j *Job) Status() Status {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.status
+}
+
+// Done 返回任务结束时关闭的通道
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Progress 获取任务进度
+func (j *Job) Progress() Progress {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	progress := Progress{Total: len(j.items)}
+	for _, item := range j.items {
+		switch item.Status {
+		case StatusPending:
+			progress.Pending++
+		case StatusRunning:
+			progress.Running++
+		case StatusCompleted:
+			progress.Completed++
+		case StatusFailed:
+			progress.Failed++
+		case StatusCancelled:
+			progress.Cancelled++
+		}
+	}
+	return progress
+}
+
+// Items 获取任务项快照 (offset/limit 分页，limit <= 0 表示全部)
+func (j *Job) Items(offset, limit int) []Item {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(j.items) {
+		offset = len(j.items)
+	}
+	end := len(j.items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	items := make([]Item, 0, end-offset)
+	for _, item := range j.items[offset:end] {
+		items = append(items, *item)
+	}
+	return items
+}
+
+// FinishedAt 获取任务结束时间 (未结束时返回 false)
+func (j *Job) FinishedAt() (time.Time, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.endedAt, j.status.IsFinal()
+}
+
+// setStatus 设置任务状态
+func (j *Job) setStatus(status Status) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+}
+
+// startItem 标记任务项开始执行
+func (j *Job) startItem(item *Item) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if item.Status == StatusPending {
+		item.Status = StatusRunning
+		item.StartedAt = time.Now()
+	}
+}
+
+// finishItem 记录任务项结果 (已结束的任务项不会被覆盖)
+func (j *Job) finishItem(item *Item, value interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if item.Status.IsFinal() {
+		return
+	}
+
+	item.FinishedAt = time.Now()
+	switch {
+	case err == nil:
+		item.Status = StatusCompleted
+		item.Result = value
+	case j.cancelled:
+		// 任务取消后中断或未执行的任务项
+		item.Status = StatusCancelled
+		item.Err = err
+	default:
+		item.Status = StatusFailed
+		item.Err = err
+	}
+}
+
+// cancelJob 取消任务
+func (j *Job) cancelJob() {
+	j.mu.Lock()
+	if !j.status.IsFinal() {
+		j.cancelled = true
+	}
+	j.mu.Unlock()
+
+	j.cancel()
+}
+
+// finish 根据任务项结果确定最终状态
+func (j *Job) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	failed := 0
+	for _, item := range j.items {
+		if item.Status == StatusFailed {
+			failed++
+		}
+	}
+
+	switch {
+	case j.cancelled:
+		j.status = StatusCancelled
+	case failed == len(j.items):
+		j.status = StatusFailed
+	default:
+		j.status = StatusCompleted
+	}
+
+	j.endedAt = time.Now()
+	j.cancel()
+	close(j.done)
+}
\ No newline at end of file
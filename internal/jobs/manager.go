@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/pool"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobNotFound 任务不存在或已过期
+	ErrJobNotFound = errors.New("job not found")
+	// ErrTooManyJobs 保留的任务数量达到上限
+	ErrTooManyJobs = errors.New("too many jobs")
+)
+
+// defaultTTL 未指定保留时间时已结束任务的保留时间
+const defaultTTL = time.Hour
+
+// ProcessFunc 处理单个任务项，ctx 在任务取消时结束
+type ProcessFunc func(ctx context.Context, input string) (interface{}, error)
+
+// Manager 异步任务管理器 (任务项在 Worker 池中执行)
+type Manager struct {
+	pool    *pool.WorkerPool
+	jobs    map[string]*Job
+	ttl     time.Duration
+	maxJobs int
+	slots   chan struct{} // 所有任务共用的 Worker 池占用名额 (为 nil 表示不限制)
+	mu      sync.Mutex
+}
+
+// NewManager 创建任务管理器
+// ttl 为已结束任务的保留时间 (不大于 0 时为 1 小时)，maxJobs 为同时保留的任务数上限 (0 表示不限制)
+// concurrency 为所有任务同时在 Worker 池中排队和执行的任务项数上限 (0 表示不限制)，
+// 避免大任务占满共享队列，使交互式请求返回 QUEUE_FULL
+func NewManager(workerPool *pool.WorkerPool, ttl time.Duration, maxJobs, concurrency int) *Manager {
+	// 已结束的任务必须过期清理，否则 maxJobs 用尽后无法再提交任务
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	var slots chan struct{}
+	if concurrency > 0 {
+		slots = make(chan struct{}, concurrency)
+	}
+
+	return &Manager{
+		pool:    workerPool,
+		jobs:    make(map[string]*Job),
+		ttl:     ttl,
+		maxJobs: maxJobs,
+		slots:   slots,
+	}
+}
+
+// Submit 创建任务并在后台处理所有任务项，owner 为提交任务的调用方 (记录在 Job.Owner 中)
+func (m *Manager) Submit(owner string, inputs []string, process ProcessFunc) (*Job, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("job has no items")
+	}
+
+	m.mu.Lock()
+	m.cleanupLocked()
+	if m.maxJobs > 0 && len(m.jobs) >= m.maxJobs {
+		m.mu.Unlock()
+		return nil, ErrTooManyJobs
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	job := newJob(id, owner, inputs)
+	m.jobs[id] = job
+	m.mu.Unlock()
+
+	logger.Info("Job submitted", zap.String("job_id", id), zap.Int("items", len(inputs)))
+
+	go m.run(job, process)
+
+	return job, nil
+}
+
+// Get 获取任务
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cleanupLocked()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Cancel 取消任务: 未开始的任务项不再执行，正在执行的任务项通过 ctx 中断
+func (m *Manager) Cancel(id string) (*Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.cancelJob()
+	logger.Info("Job cancellation requested", zap.String("job_id", id))
+
+	return job, nil
+}
+
+// Close 取消所有未结束的任务
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.jobs {
+		job.cancelJob()
+	}
+}
+
+// run 将任务项依次提交到 Worker 池 (名额用尽或队列满时等待)，并等待全部完成
+func (m *Manager) run(job *Job, process ProcessFunc) {
+	job.setStatus(StatusRunning)
+
+	futures := make([]*pool.Future, len(job.items))
+	for i, item := range job.items {
+		item := item
+		if err := m.acquire(job.ctx); err != nil {
+			// 任务已取消，剩余任务项不再提交
+			job.finishItem(item, nil, err)
+			continue
+		}
+
+		future, err := m.pool.SubmitWait(job.ctx, &itemTask{job: job, item: item, process: process})
+		if err != nil {
+			// 任务已取消或 Worker 池已停止，剩余任务项不再提交
+			m.release()
+			job.finishItem(item, nil, err)
+			continue
+		}
+		futures[i] = future
+
+		// 任务项执行完成 (或因取消被跳过) 后归还名额
+		go func() {
+			<-future.Done()
+			m.release()
+		}()
+	}
+
+	// Worker 池会跳过已取消的任务项而不调用 Execute，此处补记其结果
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
+		if result, _ := future.Wait(context.Background()); result != nil {
+			job.finishItem(job.items[i], result.Value, result.Error)
+		}
+	}
+
+	job.finish()
+
+	logger.Info("Job finished",
+		zap.String("job_id", job.ID),
+		zap.String("status", string(job.Status())),
+		zap.Duration("duration", time.Since(job.CreatedAt)),
+	)
+}
+
+// acquire 获取一个 Worker 池占用名额，ctx 结束时返回错误
+func (m *Manager) acquire(ctx context.Context) error {
+	if m.slots == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case m.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release 归还 Worker 池占用名额
+func (m *Manager) release() {
+	if m.slots != nil {
+		<-m.slots
+	}
+}
+
+// cleanupLocked 删除超过保留时间的已结束任务 (调用方需持有锁)
+func (m *Manager) cleanupLocked() {
+	now := time.Now()
+	for id, job := range m.jobs {
+		if finishedAt, ok := job.FinishedAt(); ok && now.Sub(finishedAt) > m.ttl {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// itemTask 单个任务项 (实现 pool.Task)
+type itemTask struct {
+	job     *Job
+	item    *Item
+	process ProcessFunc
+}
+
+// Execute 执行任务项并记录结果
+func (t *itemTask) Execute(ctx context.Context) (interface{}, error) {
+	t.job.startItem(t.item)
+	value, err := t.process(ctx, t.item.Input)
+	t.job.finishItem(t.item, value, err)
+
+	return value, err
+}
+
+// ID 返回任务项标识
+func (t *itemTask) ID() string {
+	return fmt.Sprintf("%s/%d", t.job.ID, t.item.Index)
+}
+
+// newJobID 生成随机任务 ID
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
\ No newline at end of file
@@ -0,0 +1,239 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/pool"
+)
+
+func newTestPool(t *testing.T, workers, queueSize int) *pool.WorkerPool {
+	t.Helper()
+
+	workerPool := pool.NewWorkerPool(workers, queueSize)
+	if err := workerPool.Start(); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	t.Cleanup(workerPool.Stop)
+
+	return workerPool
+}
+
+func waitJob(t *testing.T, job *Job) {
+	t.Helper()
+
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second * 2):
+		t.Fatal("Timeout waiting for job")
+	}
+}
+
+func TestManager_Submit(t *testing.T) {
+	manager := NewManager(newTestPool(t, 2, 10), time.Minute, 0, 0)
+
+	job, err := manager.Submit("alice", []string{"a", "bad", "c"}, func(ctx context.Context, input string) (interface{}, error) {
+		if input == "bad" {
+			return nil, errors.New("bad input")
+		}
+		return strings.ToUpper(input), nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	if job.Owner != "alice" {
+		t.Errorf("Expected owner alice, got %q", job.Owner)
+	}
+
+	waitJob(t, job)
+
+	if job.Status() != StatusCompleted {
+		t.Errorf("Expected completed, got %s", job.Status())
+	}
+
+	progress := job.Progress()
+	if progress.Total != 3 || progress.Completed != 2 || progress.Failed != 1 || progress.Done() != 3 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+
+	items := job.Items(0, 0)
+	if items[0].Result != "A" || items[2].Result != "C" {
+		t.Errorf("Unexpected results: %v, %v", items[0].Result, items[2].Result)
+	}
+	if items[1].Status != StatusFailed || items[1].Err == nil {
+		t.Errorf("Expected item 1 to fail, got %s", items[1].Status)
+	}
+
+	// 分页
+	page := job.Items(1, 1)
+	if len(page) != 1 || page[0].Index != 1 {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	found, err := manager.Get(job.ID)
+	if err != nil || found != job {
+		t.Errorf("Expected to find job %s: %v", job.ID, err)
+	}
+}
+
+func TestManager_PartialResultsAndCancel(t *testing.T) {
+	manager := NewManager(newTestPool(t, 1, 10), time.Minute, 0, 0)
+
+	started := make(chan struct{}, 10)
+	job, err := manager.Submit("", []string{"fast", "slow", "never"}, func(ctx context.Context, input string) (interface{}, error) {
+		started <- struct{}{}
+		if input == "fast" {
+			return input, nil
+		}
+		// 等待取消
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	// 第一项完成、第二项执行中时可以获取部分结果
+	<-started
+	<-started
+	progress := job.Progress()
+	if progress.Completed != 1 || progress.Running != 1 || progress.Pending != 1 {
+		t.Errorf("Unexpected progress before cancel: %+v", progress)
+	}
+	if items := job.Items(0, 1); items[0].Result != "fast" {
+		t.Errorf("Expected partial result, got %v", items[0].Result)
+	}
+
+	if _, err := manager.Cancel(job.ID); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+
+	waitJob(t, job)
+
+	if job.Status() != StatusCancelled {
+		t.Errorf("Expected cancelled, got %s", job.Status())
+	}
+
+	progress = job.Progress()
+	if progress.Completed != 1 || progress.Cancelled != 2 {
+		t.Errorf("Unexpected progress after cancel: %+v", progress)
+	}
+}
+
+func TestManager_ZeroTTL(t *testing.T) {
+	manager := NewManager(newTestPool(t, 1, 10), 0, 1, 0)
+
+	job, err := manager.Submit("", []string{"x"}, func(ctx context.Context, input string) (interface{}, error) {
+		return input, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	waitJob(t, job)
+
+	// 未超过默认保留时间的任务保留，仍占用任务数名额
+	if _, err := manager.Get(job.ID); err != nil {
+		t.Fatalf("Expected finished job to be kept, got %v", err)
+	}
+	if _, err := manager.Submit("", []string{"y"}, nil); !errors.Is(err, ErrTooManyJobs) {
+		t.Errorf("Expected ErrTooManyJobs, got %v", err)
+	}
+
+	// 超过默认保留时间后被清理，可以提交新任务
+	job.mu.Lock()
+	job.endedAt = time.Now().Add(-defaultTTL - time.Second)
+	job.mu.Unlock()
+
+	if _, err := manager.Get(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected expired job to be removed with zero TTL, got %v", err)
+	}
+
+	next, err := manager.Submit("", []string{"y"}, func(ctx context.Context, input string) (interface{}, error) {
+		return input, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected submit to succeed after cleanup, got %v", err)
+	}
+	waitJob(t, next)
+}
+
+func TestManager_NotFoundAndLimits(t *testing.T) {
+	manager := NewManager(newTestPool(t, 1, 10), time.Millisecond*10, 1, 0)
+
+	if _, err := manager.Get("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	if _, err := manager.Submit("", nil, nil); err == nil {
+		t.Error("Expected error for empty job")
+	}
+
+	block := make(chan struct{})
+	job, err := manager.Submit("", []string{"x"}, func(ctx context.Context, input string) (interface{}, error) {
+		<-block
+		return input, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	// 任务数达到上限
+	if _, err := manager.Submit("", []string{"y"}, nil); !errors.Is(err, ErrTooManyJobs) {
+		t.Errorf("Expected ErrTooManyJobs, got %v", err)
+	}
+
+	close(block)
+	waitJob(t, job)
+
+	// 已结束任务超过保留时间后被清理
+	time.Sleep(time.Millisecond * 20)
+	if _, err := manager.Get(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected expired job to be removed, got %v", err)
+	}
+}
+
+func TestManager_ConcurrencyLeavesQueueForInteractive(t *testing.T) {
+	workerPool := newTestPool(t, 1, 2)
+	manager := NewManager(workerPool, time.Minute, 0, 1)
+
+	block := make(chan struct{})
+	inputs := make([]string, 10)
+	for i := range inputs {
+		inputs[i] = "item"
+	}
+
+	job, err := manager.Submit("", inputs, func(ctx context.Context, input string) (interface{}, error) {
+		<-block
+		return input, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	// 任务最多占用一个名额，队列仍可接受交互式请求
+	if queued := workerPool.QueueSize(); queued != 0 {
+		t.Errorf("Expected job items not to wait in the queue, got %d", queued)
+	}
+	if _, err := workerPool.TrySubmit(context.Background(), interactiveTask{}); err != nil {
+		t.Errorf("Expected interactive submit to succeed, got %v", err)
+	}
+
+	close(block)
+	waitJob(t, job)
+
+	if progress := job.Progress(); progress.Completed != len(inputs) {
+		t.Errorf("Expected all items to complete, got %+v", progress)
+	}
+}
+
+// interactiveTask 模拟交互式请求
+type interactiveTask struct{}
+
+func (interactiveTask) Execute(ctx context.Context) (interface{}, error) { return nil, nil }
+
+func (interactiveTask) ID() string { return "interactive" }
\ No newline at end of file
@@ -2,6 +2,7 @@ package ocr
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -13,11 +14,20 @@ type Engine interface {
 	// RecognizeText 识别图像中的文本
 	RecognizeText(ctx context.Context, imageData []byte, opts RecognizeOptions) (*RecognizeResult, error)
 
+	// RecognizeWithDetails 识别图像并返回版面信息(包含边界框)
+	RecognizeWithDetails(ctx context.Context, imageData []byte, opts RecognizeOptions) (*DetailedResult, error)
+
 	// Close 关闭引擎并释放资源
 	Close() error
 
 	// GetSupportedLanguages 获取支持的语言列表
 	GetSupportedLanguages() []string
+
+	// Version 获取引擎版本 (用于缓存键)
+	Version() string
+
+	// DataChecksum 获取语言数据文件的校验和 (用于缓存键，无法读取时返回空字符串)
+	DataChecksum(language string) string
 }
 
 // EngineConfig 引擎配置
@@ -28,39 +38,95 @@ type EngineConfig struct {
 	EngineMode   int           // 引擎模式
 	Whitelist    string        // 字符白名单
 	Timeout      time.Duration // 超时时间
+	MaxAbandoned int           // 超时后仍在后台运行的识别数上限 (0 表示不限制)
+}
+
+// LayoutLevel 版面分析层级 (对应 Tesseract 的 PageIteratorLevel)
+type LayoutLevel string
+
+const (
+	LayoutLevelBlock     LayoutLevel = "block"     // 文本块
+	LayoutLevelParagraph LayoutLevel = "paragraph" // 段落
+	LayoutLevelLine      LayoutLevel = "line"      // 文本行
+	LayoutLevelWord      LayoutLevel = "word"      // 单词
+	LayoutLevelSymbol    LayoutLevel = "symbol"    // 字符
+)
+
+// ParseLayoutLevel 解析版面分析层级
+func ParseLayoutLevel(level string) (LayoutLevel, error) {
+	switch LayoutLevel(level) {
+	case LayoutLevelBlock, LayoutLevelParagraph, LayoutLevelLine, LayoutLevelWord, LayoutLevelSymbol:
+		return LayoutLevel(level), nil
+	case "":
+		return LayoutLevelWord, nil
+	default:
+		return "", fmt.Errorf("unsupported layout level: %s", level)
+	}
 }
 
 // RecognizeOptions 识别选项
 type RecognizeOptions struct {
 	Language    string            // 识别语言 (可覆盖默认配置)
 	PageSegMode *int              // 页面分割模式 (可覆盖默认配置)
+	Whitelist   string            // 字符白名单 (可覆盖默认配置)
 	Preprocess  bool              // 是否预处理
+	Level       LayoutLevel       // 版面分析层级 (仅 RecognizeWithDetails 使用，默认 word)
 	Metadata    map[string]string // 额外元数据
 }
 
 // RecognizeResult 识别结果
 type RecognizeResult struct {
-	Text       string            // 识别的文本
-	Confidence float64           // 置信度 (0-100)
-	Language   string            // 使用的语言
-	Duration   time.Duration     // 识别耗时
-	Metadata   map[string]string // 额外元数据
+	Text        string            // 识别的文本
+	Confidence  float64           // 置信度 (0-100)
+	Language    string            // 使用的语言
+	Duration    time.Duration     // 识别耗时
+	Metadata    map[string]string // 额外元数据
+	InputFormat string            // 输入图像格式 (根据文件头识别)
 }
 
 // BoundingBox 文本边界框
 type BoundingBox struct {
-	X      int     // X 坐标
-	Y      int     // Y 坐标
-	Width  int     // 宽度
-	Height int     // 高度
-	Text   string  // 文本内容
-	Conf   float64 // 置信度
+	X        int     // X 坐标
+	Y        int     // Y 坐标
+	Width    int     // 宽度
+	Height   int     // 高度
+	Text     string  // 文本内容
+	Conf     float64 // 置信度
+	BlockNum int     // 所属文本块编号 (仅 word 层级)
+	ParNum   int     // 所属段落编号 (仅 word 层级)
+	LineNum  int     // 所属文本行编号 (仅 word 层级)
+	WordNum  int     // 单词编号 (仅 word 层级)
 }
 
 // DetailedResult 详细识别结果(包含边界框)
 type DetailedResult struct {
 	Text        string        // 全部文本
 	Confidence  float64       // 总体置信度
+	Language    string        // 使用的语言
+	Level       LayoutLevel   // 边界框层级
+	Width       int           // 图像宽度 (像素, 0 表示未知)
+	Height      int           // 图像高度 (像素, 0 表示未知)
 	BoundingBox []BoundingBox // 文本块边界框
 	Duration    time.Duration // 识别耗时
+}
+
+// Size 估计结果占用的字节数 (用于缓存总大小限制)
+func (r *RecognizeResult) Size() int64 {
+	size := int64(len(r.Text) + len(r.Language) + len(r.InputFormat))
+	for key, value := range r.Metadata {
+		size += int64(len(key) + len(value))
+	}
+	return size
+}
+
+// boundingBoxSize 边界框固定字段占用的字节数估计 (8 个 int、1 个 float64 和字符串头)
+const boundingBoxSize = 8*8 + 8 + 16
+
+// Size 估计结果占用的字节数 (用于缓存总大小限制)
+func (r *DetailedResult) Size() int64 {
+	size := int64(len(r.Text) + len(r.Language) + len(r.Level))
+	for _, box := range r.BoundingBox {
+		size += boundingBoxSize + int64(len(box.Text))
+	}
+	return size
 }
\ No newline at end of file
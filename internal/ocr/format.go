@@ -0,0 +1,288 @@
+package ocr
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"strings"
+)
+
+// OutputFormat 识别结果输出格式
+type OutputFormat string
+
+const (
+	FormatText OutputFormat = "text" // 纯文本
+	FormatHOCR OutputFormat = "hocr" // hOCR (XHTML)
+	FormatALTO OutputFormat = "alto" // ALTO XML v4
+	FormatTSV  OutputFormat = "tsv"  // Tesseract TSV
+)
+
+// ParseOutputFormat 解析输出格式
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	switch OutputFormat(strings.ToLower(format)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatHOCR:
+		return FormatHOCR, nil
+	case FormatALTO:
+		return FormatALTO, nil
+	case FormatTSV:
+		return FormatTSV, nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// MIMEType 返回输出格式对应的 MIME 类型
+func (f OutputFormat) MIMEType() string {
+	switch f {
+	case FormatHOCR:
+		return "application/xhtml+xml"
+	case FormatALTO:
+		return "application/xml"
+	case FormatTSV:
+		return "text/tab-separated-values"
+	default:
+		return "text/plain"
+	}
+}
+
+// LayoutPage 单页版面数据 (用于生成 hOCR/ALTO/TSV)
+type LayoutPage struct {
+	Number int             // 页码 (从 1 开始)
+	Result *DetailedResult // word 层级的识别结果
+}
+
+// Render 按指定格式渲染版面数据
+func Render(format OutputFormat, pages []LayoutPage) (string, error) {
+	switch format {
+	case FormatHOCR:
+		return RenderHOCR(pages), nil
+	case FormatALTO:
+		return RenderALTO(pages), nil
+	case FormatTSV:
+		return RenderTSV(pages), nil
+	case FormatText:
+		texts := make([]string, 0, len(pages))
+		for _, page := range pages {
+			texts = append(texts, page.Result.Text)
+		}
+		return strings.Join(texts, "\f"), nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// layoutNode 版面树节点 (页面 > 文本块 > 段落 > 文本行 > 单词)
+type layoutNode struct {
+	rect     image.Rectangle
+	box      BoundingBox
+	conf     float64
+	children []*layoutNode
+}
+
+// buildLayoutTree 根据 word 层级边界框的编号构建版面树
+func buildLayoutTree(page LayoutPage) *layoutNode {
+	root := &layoutNode{}
+
+	var block, par, line *layoutNode
+	lastBlock, lastPar, lastLine := -1, -1, -1
+
+	for _, box := range page.Result.BoundingBox {
+		if strings.TrimSpace(box.Text) == "" {
+			continue
+		}
+
+		if block == nil || box.BlockNum != lastBlock {
+			block = &layoutNode{}
+			root.children = append(root.children, block)
+			lastBlock, lastPar, lastLine = box.BlockNum, -1, -1
+		}
+		if par == nil || box.ParNum != lastPar {
+			par = &layoutNode{}
+			block.children = append(block.children, par)
+			lastPar, lastLine = box.ParNum, -1
+		}
+		if line == nil || box.LineNum != lastLine {
+			line = &layoutNode{}
+			par.children = append(par.children, line)
+			lastLine = box.LineNum
+		}
+
+		rect := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+		line.children = append(line.children, &layoutNode{rect: rect, box: box, conf: box.Conf})
+
+		for _, node := range []*layoutNode{line, par, block, root} {
+			node.rect = unionRect(node.rect, rect)
+		}
+	}
+
+	// 已知图像尺寸时以整页作为页面边界
+	if page.Result.Width > 0 && page.Result.Height > 0 {
+		root.rect = image.Rect(0, 0, page.Result.Width, page.Result.Height)
+	}
+
+	return root
+}
+
+// unionRect 合并矩形 (忽略空矩形)
+func unionRect(a, b image.Rectangle) image.Rectangle {
+	if a.Empty() {
+		return b
+	}
+	return a.Union(b)
+}
+
+// RenderHOCR 生成 hOCR 文档
+func RenderHOCR(pages []LayoutPage) string {
+	var sb strings.Builder
+
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString("<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML 1.0 Transitional//EN\" \"http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd\">\n")
+	sb.WriteString("<html xmlns=\"http://www.w3.org/1999/xhtml\" xml:lang=\"en\" lang=\"en\">\n")
+	sb.WriteString(" <head>\n")
+	sb.WriteString("  <title></title>\n")
+	sb.WriteString("  <meta http-equiv=\"Content-Type\" content=\"text/html;charset=utf-8\"/>\n")
+	sb.WriteString("  <meta name=\"ocr-system\" content=\"mcp-ocr-server (tesseract)\"/>\n")
+	sb.WriteString("  <meta name=\"ocr-capabilities\" content=\"ocr_page ocr_carea ocr_par ocr_line ocrx_word\"/>\n")
+	sb.WriteString(" </head>\n")
+	sb.WriteString(" <body>\n")
+
+	for _, page := range pages {
+		root := buildLayoutTree(page)
+		p := page.Number
+		lang := html.EscapeString(page.Result.Language)
+
+		fmt.Fprintf(&sb, "  <div class=\"ocr_page\" id=\"page_%d\" title=\"bbox %s; ppageno %d\">\n", p, hocrBBox(root.rect), p-1)
+		for b, block := range root.children {
+			fmt.Fprintf(&sb, "   <div class=\"ocr_carea\" id=\"block_%d_%d\" title=\"bbox %s\">\n", p, b+1, hocrBBox(block.rect))
+			for pi, par := range block.children {
+				fmt.Fprintf(&sb, "    <p class=\"ocr_par\" id=\"par_%d_%d_%d\" lang=\"%s\" title=\"bbox %s\">\n", p, b+1, pi+1, lang, hocrBBox(par.rect))
+				for l, line := range par.children {
+					fmt.Fprintf(&sb, "     <span class=\"ocr_line\" id=\"line_%d_%d_%d_%d\" title=\"bbox %s\">", p, b+1, pi+1, l+1, hocrBBox(line.rect))
+					for w, word := range line.children {
+						if w > 0 {
+							sb.WriteString(" ")
+						}
+						fmt.Fprintf(&sb, "<span class=\"ocrx_word\" id=\"word_%d_%d_%d_%d_%d\" title=\"bbox %s; x_wconf %d\">%s</span>",
+							p, b+1, pi+1, l+1, w+1, hocrBBox(word.rect), int(word.conf+0.5), html.EscapeString(word.box.Text))
+					}
+					sb.WriteString("</span>\n")
+				}
+				sb.WriteString("    </p>\n")
+			}
+			sb.WriteString("   </div>\n")
+		}
+		sb.WriteString("  </div>\n")
+	}
+
+	sb.WriteString(" </body>\n")
+	sb.WriteString("</html>\n")
+
+	return sb.String()
+}
+
+// hocrBBox 格式化 hOCR 边界框 (x0 y0 x1 y1)
+func hocrBBox(r image.Rectangle) string {
+	return fmt.Sprintf("%d %d %d %d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+}
+
+// RenderALTO 生成 ALTO v4 XML 文档
+func RenderALTO(pages []LayoutPage) string {
+	var sb strings.Builder
+
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString("<alto xmlns=\"http://www.loc.gov/standards/alto/ns-v4#\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"http://www.loc.gov/standards/alto/ns-v4# http://www.loc.gov/alto/v4/alto-4-2.xsd\">\n")
+	sb.WriteString(" <Description>\n")
+	sb.WriteString("  <MeasurementUnit>pixel</MeasurementUnit>\n")
+	sb.WriteString("  <OCRProcessing ID=\"OCR_0\">\n")
+	sb.WriteString("   <ocrProcessingStep>\n")
+	sb.WriteString("    <processingSoftware>\n")
+	sb.WriteString("     <softwareName>mcp-ocr-server (tesseract)</softwareName>\n")
+	sb.WriteString("    </processingSoftware>\n")
+	sb.WriteString("   </ocrProcessingStep>\n")
+	sb.WriteString("  </OCRProcessing>\n")
+	sb.WriteString(" </Description>\n")
+	sb.WriteString(" <Layout>\n")
+
+	for _, page := range pages {
+		root := buildLayoutTree(page)
+		p := page.Number
+
+		fmt.Fprintf(&sb, "  <Page ID=\"page_%d\" PHYSICAL_IMG_NR=\"%d\" WIDTH=\"%d\" HEIGHT=\"%d\">\n", p, p, root.rect.Dx(), root.rect.Dy())
+		fmt.Fprintf(&sb, "   <PrintSpace %s>\n", altoPos(root.rect))
+		for b, block := range root.children {
+			// ALTO 的 TextBlock 对应段落
+			for pi, par := range block.children {
+				fmt.Fprintf(&sb, "    <TextBlock ID=\"block_%d_%d_%d\" %s LANG=\"%s\">\n", p, b+1, pi+1, altoPos(par.rect), html.EscapeString(page.Result.Language))
+				for l, line := range par.children {
+					fmt.Fprintf(&sb, "     <TextLine ID=\"line_%d_%d_%d_%d\" %s>\n", p, b+1, pi+1, l+1, altoPos(line.rect))
+					for w, word := range line.children {
+						if w > 0 {
+							prev := line.children[w-1].rect
+							fmt.Fprintf(&sb, "      <SP WIDTH=\"%d\" VPOS=\"%d\" HPOS=\"%d\"/>\n", max(word.rect.Min.X-prev.Max.X, 0), prev.Min.Y, prev.Max.X)
+						}
+						fmt.Fprintf(&sb, "      <String ID=\"string_%d_%d_%d_%d_%d\" %s WC=\"%.2f\" CONTENT=\"%s\"/>\n",
+							p, b+1, pi+1, l+1, w+1, altoPos(word.rect), word.conf/100, html.EscapeString(word.box.Text))
+					}
+					sb.WriteString("     </TextLine>\n")
+				}
+				sb.WriteString("    </TextBlock>\n")
+			}
+		}
+		sb.WriteString("   </PrintSpace>\n")
+		sb.WriteString("  </Page>\n")
+	}
+
+	sb.WriteString(" </Layout>\n")
+	sb.WriteString("</alto>\n")
+
+	return sb.String()
+}
+
+// altoPos 格式化 ALTO 位置属性
+func altoPos(r image.Rectangle) string {
+	return fmt.Sprintf("HPOS=\"%d\" VPOS=\"%d\" WIDTH=\"%d\" HEIGHT=\"%d\"", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+}
+
+// RenderTSV 生成 Tesseract 兼容的 TSV 输出
+func RenderTSV(pages []LayoutPage) string {
+	var sb strings.Builder
+
+	sb.WriteString("level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n")
+
+	row := func(level, p, b, pi, l, w int, r image.Rectangle, conf float64, text string) {
+		fmt.Fprintf(&sb, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			level, p, b, pi, l, w, r.Min.X, r.Min.Y, r.Dx(), r.Dy(), tsvConf(conf), text)
+	}
+
+	for _, page := range pages {
+		root := buildLayoutTree(page)
+		p := page.Number
+
+		row(1, p, 0, 0, 0, 0, root.rect, -1, "")
+		for b, block := range root.children {
+			row(2, p, b+1, 0, 0, 0, block.rect, -1, "")
+			for pi, par := range block.children {
+				row(3, p, b+1, pi+1, 0, 0, par.rect, -1, "")
+				for l, line := range par.children {
+					row(4, p, b+1, pi+1, l+1, 0, line.rect, -1, "")
+					for w, word := range line.children {
+						text := strings.NewReplacer("\t", " ", "\n", " ").Replace(word.box.Text)
+						row(5, p, b+1, pi+1, l+1, w+1, word.rect, word.conf, text)
+					}
+				}
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// tsvConf 格式化 TSV 置信度 (非单词行为 -1)
+func tsvConf(conf float64) string {
+	if conf < 0 {
+		return "-1"
+	}
+	return fmt.Sprintf("%.6f", conf)
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package ocr
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestLayoutPage() LayoutPage {
+	return LayoutPage{
+		Number: 1,
+		Result: &DetailedResult{
+			Text:     "Hello world\nInvoice <1>",
+			Language: "eng",
+			Level:    LayoutLevelWord,
+			Width:    200,
+			Height:   100,
+			BoundingBox: []BoundingBox{
+				{X: 10, Y: 10, Width: 40, Height: 12, Text: "Hello", Conf: 96, BlockNum: 1, ParNum: 1, LineNum: 1, WordNum: 1},
+				{X: 55, Y: 10, Width: 45, Height: 12, Text: "world", Conf: 90, BlockNum: 1, ParNum: 1, LineNum: 1, WordNum: 2},
+				{X: 10, Y: 30, Width: 50, Height: 12, Text: "Invoice", Conf: 88, BlockNum: 1, ParNum: 1, LineNum: 2, WordNum: 1},
+				{X: 65, Y: 30, Width: 20, Height: 12, Text: "<1>", Conf: 80, BlockNum: 1, ParNum: 1, LineNum: 2, WordNum: 2},
+			},
+		},
+	}
+}
+
+// assertWellFormedXML 检查文档是否为格式良好的 XML
+func assertWellFormedXML(t *testing.T, doc string) {
+	t.Helper()
+
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Document is not well-formed XML: %v", err)
+		}
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	cases := map[string]OutputFormat{
+		"":     FormatText,
+		"text": FormatText,
+		"HOCR": FormatHOCR,
+		"alto": FormatALTO,
+		"tsv":  FormatTSV,
+	}
+
+	for input, expected := range cases {
+		format, err := ParseOutputFormat(input)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, input, format)
+		}
+	}
+
+	if _, err := ParseOutputFormat("pdf"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestRenderHOCR(t *testing.T) {
+	doc := RenderHOCR([]LayoutPage{newTestLayoutPage()})
+
+	assertWellFormedXML(t, doc)
+
+	for _, expected := range []string{
+		`class="ocr_page" id="page_1" title="bbox 0 0 200 100; ppageno 0"`,
+		`class="ocr_line" id="line_1_1_1_2" title="bbox 10 30 85 42"`,
+		`title="bbox 10 10 50 22; x_wconf 96">Hello</span>`,
+		`&lt;1&gt;`,
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("Expected hOCR to contain %q", expected)
+		}
+	}
+}
+
+func TestRenderALTO(t *testing.T) {
+	doc := RenderALTO([]LayoutPage{newTestLayoutPage()})
+
+	assertWellFormedXML(t, doc)
+
+	for _, expected := range []string{
+		`<Page ID="page_1" PHYSICAL_IMG_NR="1" WIDTH="200" HEIGHT="100">`,
+		`HPOS="55" VPOS="10" WIDTH="45" HEIGHT="12" WC="0.90" CONTENT="world"`,
+		`<SP WIDTH="5" VPOS="10" HPOS="50"/>`,
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("Expected ALTO to contain %q", expected)
+		}
+	}
+}
+
+func TestRenderTSV(t *testing.T) {
+	doc := RenderTSV([]LayoutPage{newTestLayoutPage()})
+	lines := strings.Split(strings.TrimSuffix(doc, "\n"), "\n")
+
+	// header + page + block + par + 2 lines + 4 words
+	if len(lines) != 10 {
+		t.Fatalf("Expected 10 TSV rows, got %d", len(lines))
+	}
+
+	if lines[1] != "1\t1\t0\t0\t0\t0\t0\t0\t200\t100\t-1\t" {
+		t.Errorf("Unexpected page row: %q", lines[1])
+	}
+
+	if !strings.HasSuffix(lines[9], "\t80.000000\t<1>") {
+		t.Errorf("Unexpected word row: %q", lines[9])
+	}
+}
\ No newline at end of file
@@ -0,0 +1,153 @@
+package ocr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// defaultTessdataDirs 未配置 data_path 和 TESSDATA_PREFIX 时查找的常见 tessdata 目录
+var defaultTessdataDirs = []string{
+	"/usr/share/tesseract-ocr/5/tessdata",
+	"/usr/share/tesseract-ocr/4.00/tessdata",
+	"/usr/share/tessdata",
+	"/usr/local/share/tessdata",
+	"/opt/homebrew/share/tessdata",
+}
+
+// dataChecksum 语言数据文件校验和及计算前的文件状态
+type dataChecksum struct {
+	stamp string
+	sum   string
+}
+
+// DataChecksum 获取语言数据文件 (traineddata) 的 SHA256 校验和，多个语言 (如 eng+chi_sim) 合并计算
+// 结果按规范化后的语言缓存，文件大小或修改时间变化时重新计算，更新语言数据无需重启服务；
+// 找不到数据文件或语言不受支持时返回空字符串
+func (e *TesseractEngine) DataChecksum(language string) string {
+	langs, ok := e.checksumLanguages(language)
+	if !ok {
+		return ""
+	}
+
+	// 在计算前获取文件状态: 计算期间文件被替换时，下次调用会重新计算
+	key := strings.Join(langs, "+")
+	stamp := e.dataStamp(langs)
+	if cached, ok := e.checksums.Load(key); ok && cached.(dataChecksum).stamp == stamp {
+		return cached.(dataChecksum).sum
+	}
+
+	sum := e.computeDataChecksum(langs)
+	e.checksums.Store(key, dataChecksum{stamp: stamp, sum: sum})
+	return sum
+}
+
+// dataStamp 语言数据文件的大小和修改时间 (每次调用 stat，不读取文件内容)
+func (e *TesseractEngine) dataStamp(langs []string) string {
+	dir := e.tessdataDir()
+	if dir == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(dir)
+	for _, lang := range langs {
+		info, err := os.Stat(filepath.Join(dir, lang+".traineddata"))
+		if err != nil {
+			fmt.Fprintf(&sb, "|%s:missing", lang)
+			continue
+		}
+		fmt.Fprintf(&sb, "|%s:%d:%d", lang, info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String()
+}
+
+// checksumLanguages 规范化请求的语言 (去除空白和重复项，为空时使用配置的语言)
+// 语言来自请求参数: 只接受支持或配置的语言，避免校验和缓存无限增长和读取 tessdata 目录之外的文件
+func (e *TesseractEngine) checksumLanguages(language string) ([]string, bool) {
+	if strings.TrimSpace(language) == "" {
+		language = e.config.Language
+	}
+
+	configured := strings.Split(e.config.Language, "+")
+	seen := make(map[string]bool)
+	var langs []string
+	for _, lang := range strings.Split(language, "+") {
+		lang = strings.TrimSpace(lang)
+		if lang == "" || seen[lang] {
+			continue
+		}
+		if e.ValidateLanguage(lang) != nil && !containsString(configured, lang) {
+			return nil, false
+		}
+		seen[lang] = true
+		langs = append(langs, lang)
+	}
+
+	return langs, len(langs) > 0
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// computeDataChecksum 计算语言数据文件的 SHA256 校验和
+func (e *TesseractEngine) computeDataChecksum(langs []string) string {
+	dir := e.tessdataDir()
+	if dir == "" {
+		logger.Warn("Tessdata directory not found, cache keys will not include language data checksum")
+		return ""
+	}
+
+	hasher := sha256.New()
+	for _, lang := range langs {
+		path := filepath.Join(dir, lang+".traineddata")
+
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Warn("Failed to read language data", zap.String("path", path), zap.Error(err))
+			return ""
+		}
+
+		_, err = io.Copy(hasher, file)
+		file.Close()
+		if err != nil {
+			logger.Warn("Failed to read language data", zap.String("path", path), zap.Error(err))
+			return ""
+		}
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// tessdataDir tessdata 目录: 配置的 data_path、TESSDATA_PREFIX 环境变量或常见安装位置
+func (e *TesseractEngine) tessdataDir() string {
+	if e.config.DataPath != "" {
+		return e.config.DataPath
+	}
+
+	if prefix := os.Getenv("TESSDATA_PREFIX"); prefix != "" {
+		return prefix
+	}
+
+	for _, dir := range defaultTessdataDirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+
+	return ""
+}
\ No newline at end of file
@@ -1,9 +1,15 @@
 package ocr
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/otiai10/gosseract/v2"
@@ -17,6 +23,8 @@ type TesseractEngine struct {
 	config            EngineConfig
 	supportedLanguages []string
 	clientPool        *sync.Pool
+	abandoned         atomic.Int64 // 已超时但仍在运行的识别数量
+	checksums         sync.Map     // 语言 -> 语言数据文件校验和 (dataChecksum)
 	mu                sync.RWMutex
 }
 
@@ -77,65 +85,124 @@ func (e *TesseractEngine) Init(config EngineConfig) error {
 func (e *TesseractEngine) RecognizeText(ctx context.Context, imageData []byte, opts RecognizeOptions) (*RecognizeResult, error) {
 	startTime := time.Now()
 
+	var text string
+	var confidence float64
+	err := e.run(ctx, imageData, opts, func(client *gosseract.Client) error {
+		var err error
+		if text, err = client.Text(); err != nil {
+			return ocrErrors.Wrap(err, ocrErrors.ErrOCREngineFailed, "OCR recognition failed")
+		}
+
+		// 获取置信度
+		confidence = e.getConfidence(client)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(startTime)
+
+	logger.Debug("OCR recognition completed",
+		zap.Int("text_length", len(text)),
+		zap.Float64("confidence", confidence),
+		zap.Duration("duration", duration),
+	)
+
+	return &RecognizeResult{
+		Text:       text,
+		Confidence: confidence,
+		Language:   e.getLanguage(opts),
+		Duration:   duration,
+		Metadata:   opts.Metadata,
+	}, nil
+}
+
+// run 从池中获取客户端，应用配置和图像后在独立 goroutine 中执行 fn
+// ctx 没有截止时间时使用配置的超时时间，取消或超时时立即返回 TIMEOUT 错误
+// Tesseract 无法中断正在进行的识别: 超时的客户端不再放回池中 (避免被其他请求并发使用)，识别结束后关闭
+func (e *TesseractEngine) run(ctx context.Context, imageData []byte, opts RecognizeOptions, fn func(client *gosseract.Client) error) error {
+	if _, ok := ctx.Deadline(); !ok && e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	// 已取消或超时的请求不再开始识别
+	if err := ctx.Err(); err != nil {
+		return timeoutError(err)
+	}
+
 	// 从池中获取客户端
 	client := e.clientPool.Get().(*gosseract.Client)
-	defer e.clientPool.Put(client)
 
 	// 应用配置
 	if err := e.configureClient(client, opts); err != nil {
-		return nil, err
+		e.clientPool.Put(client)
+		return err
 	}
 
 	// 设置图像数据
 	if err := client.SetImageFromBytes(imageData); err != nil {
-		return nil, ocrErrors.Wrap(err, ocrErrors.ErrOCREngineFailed, "failed to set image data")
+		e.clientPool.Put(client)
+		return ocrErrors.Wrap(err, ocrErrors.ErrOCREngineFailed, "failed to set image data")
 	}
 
-	// 执行 OCR (带超时控制)
-	resultChan := make(chan struct {
-		text string
-		err  error
-	}, 1)
+	return e.execute(ctx, func() error {
+		return fn(client)
+	}, func() {
+		e.clientPool.Put(client)
+	}, func() {
+		if err := client.Close(); err != nil {
+			logger.Warn("Failed to close abandoned Tesseract client", zap.Error(err))
+		}
+	})
+}
+
+// execute 在独立 goroutine 中执行 fn，完成后调用 release 归还资源
+// 超时的 fn 继续在后台运行，结束后调用 discard；后台运行的数量达到 MaxAbandoned 时拒绝新的识别 (QUEUE_FULL)，
+// 避免反复超时的请求不断累积无法中断的 Tesseract 调用
+func (e *TesseractEngine) execute(ctx context.Context, fn func() error, release, discard func()) error {
+	if limit := int64(e.config.MaxAbandoned); limit > 0 && e.abandoned.Load() >= limit {
+		release()
+		return ocrErrors.New(ocrErrors.ErrQueueFull, "too many timed-out OCR recognitions still running").
+			WithDetails("abandoned", e.abandoned.Load()).
+			WithDetails("max_abandoned", limit)
+	}
 
+	done := make(chan error, 1)
 	go func() {
-		text, err := client.Text()
-		resultChan <- struct {
-			text string
-			err  error
-		}{text, err}
+		done <- fn()
 	}()
 
 	// 等待结果或超时
 	select {
+	case err := <-done:
+		release()
+		return err
 	case <-ctx.Done():
-		return nil, ocrErrors.New(ocrErrors.ErrTimeout, "OCR operation timeout")
-	case result := <-resultChan:
-		if result.err != nil {
-			return nil, ocrErrors.Wrap(result.err, ocrErrors.ErrOCREngineFailed, "OCR recognition failed")
-		}
-
-		// 获取置信度
-		confidence := e.getConfidence(client)
-
-		duration := time.Since(startTime)
+		e.abandoned.Add(1)
+		go e.awaitAbandoned(done, discard)
+		return timeoutError(ctx.Err())
+	}
+}
 
-		// 构建结果
-		ocrResult := &RecognizeResult{
-			Text:       result.text,
-			Confidence: confidence,
-			Language:   e.getLanguage(opts),
-			Duration:   duration,
-			Metadata:   opts.Metadata,
-		}
+// awaitAbandoned 等待超时的识别结束后调用 discard
+func (e *TesseractEngine) awaitAbandoned(done <-chan error, discard func()) {
+	abandonedAt := time.Now()
+	<-done
+	e.abandoned.Add(-1)
+	discard()
 
-		logger.Debug("OCR recognition completed",
-			zap.Int("text_length", len(result.text)),
-			zap.Float64("confidence", confidence),
-			zap.Duration("duration", duration),
-		)
+	logger.Warn("Timed-out OCR recognition finished",
+		zap.Duration("overrun", time.Since(abandonedAt)),
+		zap.Int64("still_running", e.abandoned.Load()),
+	)
+}
 
-		return ocrResult, nil
-	}
+// timeoutError 将 context 错误转换为 OCR 超时错误
+func timeoutError(err error) error {
+	return ocrErrors.Wrap(err, ocrErrors.ErrTimeout, "OCR operation timed out or was cancelled")
 }
 
 // Close 关闭引擎
@@ -156,6 +223,11 @@ func (e *TesseractEngine) GetSupportedLanguages() []string {
 	return e.supportedLanguages
 }
 
+// Version 获取 Tesseract 版本
+func (e *TesseractEngine) Version() string {
+	return gosseract.Version()
+}
+
 // configureClient 配置客户端
 func (e *TesseractEngine) configureClient(client *gosseract.Client, opts RecognizeOptions) error {
 	// 设置语言
@@ -175,10 +247,12 @@ func (e *TesseractEngine) configureClient(client *gosseract.Client, opts Recogni
 		}
 	}
 
-	// 设置白名单
-	if e.config.Whitelist != "" {
-		client.SetWhitelist(e.config.Whitelist)
+	// 设置白名单 (客户端会被复用，因此总是设置以清除上一次请求的白名单)
+	whitelist := e.config.Whitelist
+	if opts.Whitelist != "" {
+		whitelist = opts.Whitelist
 	}
+	client.SetWhitelist(whitelist)
 
 	return nil
 }
@@ -206,74 +280,92 @@ func (e *TesseractEngine) getConfidence(client *gosseract.Client) float64 {
 func (e *TesseractEngine) RecognizeWithDetails(ctx context.Context, imageData []byte, opts RecognizeOptions) (*DetailedResult, error) {
 	startTime := time.Now()
 
-	client := e.clientPool.Get().(*gosseract.Client)
-	defer e.clientPool.Put(client)
+	level := opts.Level
+	if level == "" {
+		level = LayoutLevelWord
+	}
 
-	// 应用配置
-	if err := e.configureClient(client, opts); err != nil {
+	var text string
+	var boxes []gosseract.BoundingBox
+	err := e.run(ctx, imageData, opts, func(client *gosseract.Client) error {
+		var err error
+		if boxes, err = e.getBoundingBoxes(client, level); err != nil {
+			return ocrErrors.Wrap(err, ocrErrors.ErrOCREngineFailed, "OCR recognition failed")
+		}
+
+		// 获取全文本
+		text, _ = client.Text()
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// 设置图像数据
-	if err := client.SetImageFromBytes(imageData); err != nil {
-		return nil, ocrErrors.Wrap(err, ocrErrors.ErrOCREngineFailed, "failed to set image data")
+	// 转换边界框
+	boundingBoxes := make([]BoundingBox, 0, len(boxes))
+	var totalConf float64
+	for _, box := range boxes {
+		boundingBoxes = append(boundingBoxes, BoundingBox{
+			X:        box.Box.Min.X,
+			Y:        box.Box.Min.Y,
+			Width:    box.Box.Max.X - box.Box.Min.X,
+			Height:   box.Box.Max.Y - box.Box.Min.Y,
+			Text:     box.Word,
+			Conf:     box.Confidence,
+			BlockNum: box.BlockNum,
+			ParNum:   box.ParNum,
+			LineNum:  box.LineNum,
+			WordNum:  box.WordNum,
+		})
+		totalConf += box.Confidence
 	}
 
-	// 执行 OCR
-	resultChan := make(chan struct {
-		boxes gosseract.BoundingBoxes
-		err   error
-	}, 1)
-
-	go func() {
-		boxes, err := client.GetBoundingBoxes(gosseract.RIL_WORD)
-		resultChan <- struct {
-			boxes gosseract.BoundingBoxes
-			err   error
-		}{boxes, err}
-	}()
+	// 计算平均置信度
+	avgConf := 0.0
+	if len(boundingBoxes) > 0 {
+		avgConf = totalConf / float64(len(boundingBoxes))
+	}
 
-	// 等待结果或超时
-	select {
-	case <-ctx.Done():
-		return nil, ocrErrors.New(ocrErrors.ErrTimeout, "OCR operation timeout")
-	case result := <-resultChan:
-		if result.err != nil {
-			return nil, ocrErrors.Wrap(result.err, ocrErrors.ErrOCREngineFailed, "OCR recognition failed")
-		}
+	// 获取图像尺寸 (用于 hOCR/ALTO 页面边界)
+	width, height := 0, 0
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(imageData)); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
 
-		// 获取全文本
-		text, _ := client.Text()
-
-		// 转换边界框
-		boundingBoxes := make([]BoundingBox, 0, len(result.boxes))
-		var totalConf float64
-		for _, box := range result.boxes {
-			boundingBoxes = append(boundingBoxes, BoundingBox{
-				X:      box.Box.Min.X,
-				Y:      box.Box.Min.Y,
-				Width:  box.Box.Max.X - box.Box.Min.X,
-				Height: box.Box.Max.Y - box.Box.Min.Y,
-				Text:   box.Word,
-				Conf:   float64(box.Confidence),
-			})
-			totalConf += float64(box.Confidence)
-		}
+	duration := time.Since(startTime)
 
-		// 计算平均置信度
-		avgConf := 0.0
-		if len(boundingBoxes) > 0 {
-			avgConf = totalConf / float64(len(boundingBoxes))
-		}
+	logger.Debug("OCR layout recognition completed",
+		zap.String("level", string(level)),
+		zap.Int("boxes", len(boundingBoxes)),
+		zap.Duration("duration", duration),
+	)
 
-		duration := time.Since(startTime)
+	return &DetailedResult{
+		Text:        text,
+		Confidence:  avgConf,
+		Language:    e.getLanguage(opts),
+		Level:       level,
+		Width:       width,
+		Height:      height,
+		BoundingBox: boundingBoxes,
+		Duration:    duration,
+	}, nil
+}
 
-		return &DetailedResult{
-			Text:        text,
-			Confidence:  avgConf,
-			BoundingBox: boundingBoxes,
-			Duration:    duration,
-		}, nil
+// getBoundingBoxes 按层级获取边界框
+// word 层级使用 verbose 接口，以便同时获得块/段落/行编号
+func (e *TesseractEngine) getBoundingBoxes(client *gosseract.Client, level LayoutLevel) ([]gosseract.BoundingBox, error) {
+	switch level {
+	case LayoutLevelBlock:
+		return client.GetBoundingBoxes(gosseract.RIL_BLOCK)
+	case LayoutLevelParagraph:
+		return client.GetBoundingBoxes(gosseract.RIL_PARA)
+	case LayoutLevelLine:
+		return client.GetBoundingBoxes(gosseract.RIL_TEXTLINE)
+	case LayoutLevelSymbol:
+		return client.GetBoundingBoxes(gosseract.RIL_SYMBOL)
+	default:
+		return client.GetBoundingBoxesVerbose()
 	}
 }
 
@@ -2,8 +2,14 @@ package ocr
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
 )
 
 func TestTesseractEngine_Init(t *testing.T) {
@@ -79,4 +85,122 @@ func TestTesseractEngine_RecognizeText(t *testing.T) {
 
 	// t.Logf("Recognized text: %s", result.Text)
 	// t.Logf("Confidence: %.2f", result.Confidence)
+}
+
+// errorCode 获取 OCR 错误码
+func errorCode(err error) ocrErrors.ErrorCode {
+	var ocrErr *ocrErrors.OCRError
+	if errors.As(err, &ocrErr) {
+		return ocrErr.Code
+	}
+	return ""
+}
+
+func TestTesseractEngine_AbandonedRunsBounded(t *testing.T) {
+	engine := NewTesseractEngine()
+	engine.config.MaxAbandoned = 2
+
+	release := make(chan struct{})
+	var started, released, discarded atomic.Int32
+	block := func() error {
+		started.Add(1)
+		<-release
+		return nil
+	}
+	execute := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		return engine.execute(ctx, block, func() { released.Add(1) }, func() { discarded.Add(1) })
+	}
+
+	// 超时的识别继续在后台运行，直到达到上限
+	for i := 0; i < 2; i++ {
+		if err := execute(); errorCode(err) != ocrErrors.ErrTimeout {
+			t.Fatalf("Run %d: expected TIMEOUT, got %v", i, err)
+		}
+	}
+
+	// 达到上限后拒绝新的识别，不再启动新的后台调用
+	for i := 0; i < 5; i++ {
+		if err := execute(); errorCode(err) != ocrErrors.ErrQueueFull {
+			t.Fatalf("Expected QUEUE_FULL above max_abandoned, got %v", err)
+		}
+	}
+	if started.Load() != 2 {
+		t.Errorf("Expected only 2 runs to start, got %d", started.Load())
+	}
+	if released.Load() != 5 {
+		t.Errorf("Expected rejected runs to release their client, got %d", released.Load())
+	}
+
+	// 后台调用结束后恢复接受新的识别
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for engine.abandoned.Load() > 0 || discarded.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for abandoned runs, still running %d", engine.abandoned.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := execute(); err != nil {
+		t.Errorf("Expected run to succeed after abandoned runs finished, got %v", err)
+	}
+}
+
+func TestTesseractEngine_DataChecksum(t *testing.T) {
+	dir := t.TempDir()
+	for _, lang := range []string{"eng", "chi_sim"} {
+		if err := os.WriteFile(filepath.Join(dir, lang+".traineddata"), []byte(lang), 0644); err != nil {
+			t.Fatalf("Failed to write language data: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.traineddata"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write language data: %v", err)
+	}
+
+	engine := NewTesseractEngine()
+	engine.config.DataPath = dir
+	engine.config.Language = "eng"
+
+	sum := engine.DataChecksum("eng+chi_sim")
+	if sum == "" {
+		t.Fatal("Expected checksum for supported languages")
+	}
+	if other := engine.DataChecksum(" eng + chi_sim+eng "); other != sum {
+		t.Errorf("Expected equivalent language strings to share checksum, got %q and %q", sum, other)
+	}
+	if engine.DataChecksum("") != engine.DataChecksum("eng") {
+		t.Error("Expected empty language to use the configured language")
+	}
+
+	// 不支持的语言不计算也不缓存
+	for _, lang := range []string{"secret", "eng+secret", "../eng", "+"} {
+		if sum := engine.DataChecksum(lang); sum != "" {
+			t.Errorf("Language %q: expected empty checksum, got %q", lang, sum)
+		}
+	}
+
+	cached := 0
+	engine.checksums.Range(func(key, value interface{}) bool {
+		cached++
+		return true
+	})
+	if cached != 2 {
+		t.Errorf("Expected 2 cached checksums, got %d", cached)
+	}
+
+	// 更新语言数据后重新计算校验和
+	path := filepath.Join(dir, "eng.traineddata")
+	if err := os.WriteFile(path, []byte("eng v2"), 0644); err != nil {
+		t.Fatalf("Failed to update language data: %v", err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to update modification time: %v", err)
+	}
+
+	if updated := engine.DataChecksum("eng+chi_sim"); updated == sum || updated == "" {
+		t.Errorf("Expected checksum to change after language data update, got %q", updated)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,219 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// Word 页面中的单词 (像素坐标，原点在图像左上角)
+type Word struct {
+	Text   string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Page PDF 页面: 原始图像 + 不可见文本层
+type Page struct {
+	JPEG  []byte  // 页面图像 (JPEG 编码)
+	DPI   float64 // 图像分辨率，用于计算页面物理尺寸 (0 表示 300)
+	Words []Word  // 文本层单词，坐标相对于 JPEG 图像
+}
+
+const (
+	defaultDPI = 300.0
+
+	// avgCharWidth 文本层字体的字宽 (em)，与 CIDFont 的 /DW 一致，用于估算水平缩放
+	avgCharWidth = 0.5
+
+	// firstPageID 第一个页面对象的编号 (之前为 Catalog、Pages 和文本层字体对象)
+	firstPageID = 7
+)
+
+// writer PDF 对象写入器 (记录每个对象的偏移量以生成 xref)
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// Write 生成可搜索 PDF: 每页绘制原始图像，并叠加与单词边界框对齐的不可见文本层
+//
+// 文本层使用 Identity-H 编码的无字形 CID 字体 (不嵌入字体程序，文本不可见)，字符编码为 Unicode 码点，
+// 并通过 ToUnicode CMap 映射回 Unicode，因此 CJK 等非拉丁文本同样可以搜索和复制。
+// 基本多文种平面以外的字符会被替换为 '?'。
+func Write(out io.Writer, pages []Page) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to write")
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象编号: 1=Catalog, 2=Pages, 3=Type0 字体, 4=CIDFont, 5=FontDescriptor, 6=ToUnicode,
+	// 之后每页 3 个对象 (Page, Contents, Image)
+	pageRefs := make([]string, 0, len(pages))
+	for i := range pages {
+		pageRefs = append(pageRefs, fmt.Sprintf("%d 0 R", firstPageID+i*3))
+	}
+
+	w.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageRefs, " "), len(pages)))
+	w.object(3, "<< /Type /Font /Subtype /Type0 /BaseFont /GlyphLessFont /Encoding /Identity-H /DescendantFonts [4 0 R] /ToUnicode 6 0 R >>")
+	w.object(4, "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /GlyphLessFont /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor 5 0 R /DW 500 /CIDToGIDMap /Identity >>")
+	w.object(5, "<< /Type /FontDescriptor /FontName /GlyphLessFont /Flags 4 /FontBBox [0 0 500 1000] /ItalicAngle 0 /Ascent 1000 /Descent 0 /CapHeight 1000 /StemV 80 >>")
+	w.stream(6, "", toUnicodeCMap())
+
+	for i, page := range pages {
+		if err := w.page(firstPageID+i*3, page); err != nil {
+			return fmt.Errorf("page %d: %w", i+1, err)
+		}
+	}
+
+	// 交叉引用表
+	xrefOffset := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n", len(w.offsets)+1)
+	w.buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, xrefOffset)
+
+	_, err := out.Write(w.buf.Bytes())
+	return err
+}
+
+// page 写入单个页面的 Page、Contents 和 Image 对象
+func (w *writer) page(id int, page Page) error {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(page.JPEG))
+	if err != nil {
+		return fmt.Errorf("invalid JPEG image: %w", err)
+	}
+
+	dpi := page.DPI
+	if dpi <= 0 {
+		dpi = defaultDPI
+	}
+	scale := 72.0 / dpi
+	pageWidth := float64(cfg.Width) * scale
+	pageHeight := float64(cfg.Height) * scale
+
+	contentsID, imageID := id+1, id+2
+
+	w.object(id, fmt.Sprintf(
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> /XObject << /Im1 %d 0 R >> >> /Contents %d 0 R >>",
+		num(pageWidth), num(pageHeight), imageID, contentsID,
+	))
+
+	// 内容流: 绘制图像 + 不可见文本 (渲染模式 3)
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "q %s 0 0 %s 0 0 cm /Im1 Do Q\n", num(pageWidth), num(pageHeight))
+	content.WriteString("BT 3 Tr\n")
+	for _, word := range page.Words {
+		text, glyphs := encodeText(word.Text)
+		if glyphs == 0 || word.Width <= 0 || word.Height <= 0 {
+			continue
+		}
+
+		fontSize := float64(word.Height) * scale
+		wordWidth := float64(word.Width) * scale
+		hScale := 100 * wordWidth / (float64(glyphs) * avgCharWidth * fontSize)
+		x := float64(word.X) * scale
+		// PDF 原点在左下角，基线取边界框底部
+		y := pageHeight - float64(word.Y+word.Height)*scale
+
+		fmt.Fprintf(&content, "/F1 %s Tf %s Tz 1 0 0 1 %s %s Tm <%s> Tj\n",
+			num(fontSize), num(hScale), num(x), num(y), text)
+	}
+	content.WriteString("ET\n")
+
+	w.stream(contentsID, "", content.Bytes())
+	w.stream(imageID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode",
+		cfg.Width, cfg.Height, colorSpace(cfg.ColorModel)), page.JPEG)
+
+	return nil
+}
+
+// object 写入间接对象
+func (w *writer) object(id int, body string) {
+	w.startObject(id)
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream 写入流对象
+func (w *writer) stream(id int, dict string, data []byte) {
+	w.startObject(id)
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, strings.TrimSpace(dict), len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// startObject 记录对象偏移量 (对象按编号顺序写入)
+func (w *writer) startObject(id int) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.buf.Len()
+}
+
+// colorSpace 根据 JPEG 颜色模型选择 PDF 色彩空间
+func colorSpace(model color.Model) string {
+	switch model {
+	case color.GrayModel:
+		return "DeviceGray"
+	case color.CMYKModel:
+		return "DeviceCMYK"
+	default:
+		return "DeviceRGB"
+	}
+}
+
+// encodeText 将文本编码为 Identity-H 字符码 (2 字节 Unicode 码点，十六进制)，返回编码和字符数
+func encodeText(text string) (string, int) {
+	var sb strings.Builder
+	glyphs := 0
+	for _, r := range strings.TrimSpace(text) {
+		if r < 0x20 {
+			continue
+		}
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&sb, "%04X", r)
+		glyphs++
+	}
+	return sb.String(), glyphs
+}
+
+// toUnicodeCMap 生成将 2 字节字符码映射为同值 Unicode 码点的 ToUnicode CMap
+func toUnicodeCMap() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// bfrange 的起止码只能在最后一个字节不同，且每段最多 100 项，因此按高字节分段
+	for start := 0; start < 256; start += 100 {
+		end := min(start+100, 256)
+		fmt.Fprintf(&buf, "%d beginbfrange\n", end-start)
+		for hi := start; hi < end; hi++ {
+			fmt.Fprintf(&buf, "<%02X00> <%02XFF> <%02X00>\n", hi, hi, hi)
+		}
+		buf.WriteString("endbfrange\n")
+	}
+
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return buf.Bytes()
+}
+
+// num 格式化 PDF 数值
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.Set(1, 1, color.Black)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWrite(t *testing.T) {
+	pages := []Page{
+		{
+			JPEG: newTestJPEG(t, 600, 300),
+			DPI:  300,
+			Words: []Word{
+				{Text: "Hello", X: 30, Y: 30, Width: 150, Height: 30},
+				{Text: "(world)", X: 200, Y: 30, Width: 180, Height: 30},
+				{Text: "你好", X: 30, Y: 90, Width: 60, Height: 30},
+			},
+		},
+		{
+			JPEG: newTestJPEG(t, 300, 300),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, pages); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	doc := buf.String()
+
+	if !strings.HasPrefix(doc, "%PDF-1.4") || !strings.HasSuffix(doc, "%%EOF\n") {
+		t.Error("Expected PDF header and trailer")
+	}
+
+	for _, expected := range []string{
+		"/Count 2",
+		"/MediaBox [0 0 144 72]",
+		"/ColorSpace /DeviceGray",
+		"BT 3 Tr",
+		"/Encoding /Identity-H",
+		"/ToUnicode 6 0 R",
+		"<0000> <FFFF>",
+		"<00480065006C006C006F> Tj",         // Hello
+		"<00280077006F0072006C00640029> Tj", // (world)
+		"<4F60597D> Tj",                     // 你好
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("Expected PDF to contain %q", expected)
+		}
+	}
+
+	// 校验 xref 表中的偏移量指向对应对象
+	xrefStart := strings.LastIndex(doc, "\nxref\n") + 1
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(doc[xrefStart:], -1)
+	if len(entries) != firstPageID-1+len(pages)*3 {
+		t.Fatalf("Expected %d xref entries, got %d", firstPageID-1+len(pages)*3, len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if !strings.HasPrefix(doc[offset:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("xref entry %d does not point to object start", i+1)
+		}
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, nil); err == nil {
+		t.Error("Expected error for empty page list")
+	}
+
+	if err := Write(&buf, []Page{{JPEG: []byte("not a jpeg")}}); err == nil {
+		t.Error("Expected error for invalid JPEG")
+	}
+}
\ No newline at end of file
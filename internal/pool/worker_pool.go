@@ -2,6 +2,7 @@ package pool
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -10,6 +11,13 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrQueueFull 任务队列已满
+	ErrQueueFull = errors.New("task queue full")
+	// ErrPoolStopped Worker 池已停止或未启动
+	ErrPoolStopped = errors.New("worker pool not running")
+)
+
 // Task 任务接口
 type Task interface {
 	Execute(ctx context.Context) (interface{}, error)
@@ -24,15 +32,45 @@ type Result struct {
 	Duration time.Duration
 }
 
+// Future 异步任务的执行结果
+type Future struct {
+	done   chan struct{}
+	result *Result
+}
+
+// Done 返回任务完成时关闭的通道
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait 等待任务完成并返回结果
+func (f *Future) Wait(ctx context.Context) (*Result, error) {
+	select {
+	case <-f.done:
+		return f.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// job 队列中的任务 (future 为 nil 时结果发送到公共结果通道)
+type job struct {
+	task   Task
+	ctx    context.Context
+	future *Future
+}
+
 // WorkerPool Worker 池
 type WorkerPool struct {
 	workerCount int
-	taskQueue   chan Task
+	taskQueue   chan *job
 	resultQueue chan *Result
 	wg          sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
 	started     bool
+	stopping    chan struct{} // Stop 开始时关闭，唤醒阻塞在 SubmitWait 中的提交方
+	stopOnce    sync.Once
 	mu          sync.RWMutex
 }
 
@@ -42,11 +80,12 @@ func NewWorkerPool(workerCount, queueSize int) *WorkerPool {
 
 	return &WorkerPool{
 		workerCount: workerCount,
-		taskQueue:   make(chan Task, queueSize),
+		taskQueue:   make(chan *job, queueSize),
 		resultQueue: make(chan *Result, queueSize),
 		ctx:         ctx,
 		cancel:      cancel,
 		started:     false,
+		stopping:    make(chan struct{}),
 	}
 }
 
@@ -70,8 +109,13 @@ func (p *WorkerPool) Start() error {
 	return nil
 }
 
-// Stop 停止 Worker 池
+// Stop 停止 Worker 池 (等待已入队的任务执行完成)
 func (p *WorkerPool) Stop() {
+	// 先让阻塞提交的调用方返回并释放读锁，否则关闭要等到它们的 ctx 结束
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+	})
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -95,7 +139,8 @@ func (p *WorkerPool) Stop() {
 	logger.Info("Worker pool stopped")
 }
 
-// Submit 提交任务
+// Submit 提交任务，结果通过 Results() 通道返回
+// 队列已满时立即返回 ErrQueueFull
 func (p *WorkerPool) Submit(task Task) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -105,12 +150,60 @@ func (p *WorkerPool) Submit(task Task) error {
 	}
 
 	select {
-	case p.taskQueue <- task:
+	case p.taskQueue <- &job{task: task}:
 		return nil
 	case <-p.ctx.Done():
-		return fmt.Errorf("worker pool stopped")
+		return ErrPoolStopped
+	default:
+		return ErrQueueFull
+	}
+}
+
+// TrySubmit 提交任务并返回 Future，队列已满时立即返回 ErrQueueFull
+// 任务使用 ctx 执行，ctx 在任务出队前结束时不再执行
+func (p *WorkerPool) TrySubmit(ctx context.Context, task Task) (*Future, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.started {
+		return nil, ErrPoolStopped
+	}
+
+	j := newJob(ctx, task)
+	select {
+	case p.taskQueue <- j:
+		return j.future, nil
 	default:
-		return fmt.Errorf("task queue full")
+		return nil, ErrQueueFull
+	}
+}
+
+// SubmitWait 提交任务并返回 Future，队列已满时阻塞等待空位 (背压)，直到 ctx 结束或 Worker 池停止
+func (p *WorkerPool) SubmitWait(ctx context.Context, task Task) (*Future, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.started {
+		return nil, ErrPoolStopped
+	}
+
+	j := newJob(ctx, task)
+	select {
+	case p.taskQueue <- j:
+		return j.future, nil
+	case <-p.stopping:
+		return nil, ErrPoolStopped
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newJob 创建带 Future 的任务
+func newJob(ctx context.Context, task Task) *job {
+	return &job{
+		task:   task,
+		ctx:    ctx,
+		future: &Future{done: make(chan struct{})},
 	}
 }
 
@@ -127,22 +220,19 @@ func (p *WorkerPool) worker(id int) {
 
 	for {
 		select {
-		case task, ok := <-p.taskQueue:
+		case j, ok := <-p.taskQueue:
 			if !ok {
 				logger.Debug("Worker stopped", zap.Int("worker_id", id))
 				return
 			}
 
-			// 执行任务
-			startTime := time.Now()
-			value, err := task.Execute(p.ctx)
-			duration := time.Since(startTime)
+			result := p.execute(j)
 
-			result := &Result{
-				TaskID:   task.ID(),
-				Value:    value,
-				Error:    err,
-				Duration: duration,
+			// 通过 Future 返回结果
+			if j.future != nil {
+				j.future.result = result
+				close(j.future.done)
+				continue
 			}
 
 			// 发送结果
@@ -159,6 +249,44 @@ func (p *WorkerPool) worker(id int) {
 	}
 }
 
+// execute 执行任务 (调用方已取消的任务直接返回取消错误)
+// 任务 panic 时返回错误结果，避免 Worker 退出和 Future 永远不完成
+func (p *WorkerPool) execute(j *job) (result *Result) {
+	ctx := j.ctx
+	if ctx == nil {
+		ctx = p.ctx
+	}
+
+	if err := ctx.Err(); err != nil {
+		return &Result{TaskID: j.task.ID(), Error: err}
+	}
+
+	startTime := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Task panicked",
+				zap.String("task_id", j.task.ID()),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+			result = &Result{
+				TaskID:   j.task.ID(),
+				Error:    fmt.Errorf("task panicked: %v", r),
+				Duration: time.Since(startTime),
+			}
+		}
+	}()
+
+	value, err := j.task.Execute(ctx)
+
+	return &Result{
+		TaskID:   j.task.ID(),
+		Value:    value,
+		Error:    err,
+		Duration: time.Since(startTime),
+	}
+}
+
 // Stats 获取统计信息
 func (p *WorkerPool) Stats() map[string]interface{} {
 	p.mu.RLock()
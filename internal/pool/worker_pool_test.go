@@ -3,6 +3,7 @@ package pool
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -138,4 +139,187 @@ func TestWorkerPool_Multiple(t *testing.T) {
 	if len(results) != taskCount {
 		t.Errorf("Expected %d results, got %d", taskCount, len(results))
 	}
+}
+
+func TestWorkerPool_TrySubmitFuture(t *testing.T) {
+	pool := NewWorkerPool(2, 10)
+	pool.Start()
+	defer pool.Stop()
+
+	future, err := pool.TrySubmit(context.Background(), &MockTask{id: "future"})
+	if err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	result, err := future.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for result: %v", err)
+	}
+	if result.Value != "result-future" {
+		t.Errorf("Expected result-future, got %v", result.Value)
+	}
+}
+
+// panicTask 执行时 panic 的任务
+type panicTask struct{}
+
+func (t *panicTask) Execute(ctx context.Context) (interface{}, error) {
+	var m map[string]int
+	m["key"]++
+	return nil, nil
+}
+
+func (t *panicTask) ID() string {
+	return "panic"
+}
+
+func TestWorkerPool_TaskPanic(t *testing.T) {
+	pool := NewWorkerPool(1, 10)
+	pool.Start()
+	defer pool.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	future, err := pool.TrySubmit(ctx, &panicTask{})
+	if err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+
+	result, err := future.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Expected panicking task to complete, got %v", err)
+	}
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "task panicked") {
+		t.Errorf("Expected task panicked error, got %v", result.Error)
+	}
+
+	// Worker 在 panic 后继续处理任务
+	future, err = pool.TrySubmit(ctx, &MockTask{id: "after"})
+	if err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+	result, err = future.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for result: %v", err)
+	}
+	if result.Value != "result-after" {
+		t.Errorf("Expected result-after, got %v", result.Value)
+	}
+}
+
+func TestWorkerPool_QueueFull(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	pool.Start()
+	defer pool.Stop()
+
+	ctx := context.Background()
+	slow := &MockTask{id: "slow", duration: time.Millisecond * 200}
+
+	// 第一个任务占用 worker，第二个任务占满队列
+	if _, err := pool.TrySubmit(ctx, slow); err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+	time.Sleep(time.Millisecond * 20)
+	if _, err := pool.TrySubmit(ctx, slow); err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+
+	if _, err := pool.TrySubmit(ctx, slow); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	// 阻塞提交在超时前无法获得队列空位
+	waitCtx, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	if _, err := pool.SubmitWait(waitCtx, slow); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	// 阻塞提交在队列腾出空位后成功
+	future, err := pool.SubmitWait(ctx, &MockTask{id: "queued"})
+	if err != nil {
+		t.Fatalf("Failed to submit task with backpressure: %v", err)
+	}
+
+	result, err := future.Wait(ctx)
+	if err != nil || result.Value != "result-queued" {
+		t.Errorf("Expected result-queued, got %v (err: %v)", result, err)
+	}
+}
+
+func TestWorkerPool_CancelledTask(t *testing.T) {
+	pool := NewWorkerPool(1, 10)
+	pool.Start()
+	defer pool.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	future, err := pool.TrySubmit(ctx, &MockTask{id: "cancelled"})
+	if err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for cancelled task")
+	}
+
+	result, _ := future.Wait(context.Background())
+	if !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", result.Error)
+	}
+	if result.Value != nil {
+		t.Errorf("Cancelled task should not be executed, got %v", result.Value)
+	}
+}
+
+func TestWorkerPool_NotStarted(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+
+	if _, err := pool.TrySubmit(context.Background(), &MockTask{id: "x"}); !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("Expected ErrPoolStopped, got %v", err)
+	}
+}
+
+func TestWorkerPool_StopUnblocksSubmitWait(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	pool.Start()
+
+	ctx := context.Background()
+	slow := &MockTask{id: "slow", duration: time.Millisecond * 100}
+
+	// 占用 worker 和队列
+	pool.TrySubmit(ctx, slow)
+	time.Sleep(time.Millisecond * 20)
+	pool.TrySubmit(ctx, slow)
+
+	// 没有超时的阻塞提交
+	submitErr := make(chan error, 1)
+	go func() {
+		_, err := pool.SubmitWait(ctx, slow)
+		submitErr <- err
+	}()
+	time.Sleep(time.Millisecond * 20)
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked on a waiting submitter")
+	}
+
+	if err := <-submitErr; !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("Expected ErrPoolStopped, got %v", err)
+	}
 }
\ No newline at end of file
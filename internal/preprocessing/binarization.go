@@ -6,43 +6,63 @@ import (
 	"gocv.io/x/gocv"
 )
 
+func init() {
+	Register("binarization", map[string]ParamSpec{
+		"mode":       {Kind: ParamString, Values: []string{string(MethodOtsu), string(MethodAdaptive)}},
+		"block_size": {Kind: ParamInt, Min: 3, Max: 255, Odd: true}, // 自适应阈值块大小
+		"c":          {Kind: ParamFloat, Min: -100, Max: 100},
+	}, func(step Step, cfg Config) (Processor, error) {
+		return NewBinarizationProcessor(
+			BinarizationMethod(step.StringParam("mode", cfg.BinarizationMode)),
+			step.IntParam("block_size", cfg.AdaptiveBlockSize),
+			step.FloatParam("c", cfg.AdaptiveC),
+		), nil
+	})
+}
+
 // BinarizationMethod 二值化方法
 type BinarizationMethod string
 
 const (
-	MethodOtsu      BinarizationMethod = "otsu"
-	MethodAdaptive  BinarizationMethod = "adaptive"
-	MethodThreshold BinarizationMethod = "threshold"
+	MethodOtsu     BinarizationMethod = "otsu"
+	MethodAdaptive BinarizationMethod = "adaptive"
 )
 
 // BinarizationProcessor 二值化处理器
 type BinarizationProcessor struct {
 	method    BinarizationMethod
-	threshold float64
+	blockSize int
+	c         float64
 }
 
-// NewBinarizationProcessor 创建二值化处理器
-func NewBinarizationProcessor(method BinarizationMethod, threshold float64) *BinarizationProcessor {
-	if threshold == 0 {
-		threshold = 127
+// NewBinarizationProcessor 创建二值化处理器 (blockSize 和 c 仅用于自适应阈值)
+func NewBinarizationProcessor(method BinarizationMethod, blockSize int, c float64) *BinarizationProcessor {
+	if blockSize < 3 {
+		blockSize = 11
+	}
+	// 确保块大小是奇数
+	if blockSize%2 == 0 {
+		blockSize++
 	}
 	return &BinarizationProcessor{
 		method:    method,
-		threshold: threshold,
+		blockSize: blockSize,
+		c:         c,
 	}
 }
 
 // Process 执行二值化处理
 func (p *BinarizationProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
+	gray := toGray(input)
+	defer gray.Close()
+
 	output := gocv.NewMat()
 
 	switch p.method {
-	case MethodOtsu:
-		gocv.Threshold(input, &output, 0, 255, gocv.ThresholdBinary|gocv.ThresholdOtsu)
 	case MethodAdaptive:
-		gocv.AdaptiveThreshold(input, &output, 255, gocv.AdaptiveThresholdMean, gocv.ThresholdBinary, 11, 2)
+		gocv.AdaptiveThreshold(gray, &output, 255, gocv.AdaptiveThresholdMean, gocv.ThresholdBinary, p.blockSize, float32(p.c))
 	default:
-		gocv.Threshold(input, &output, p.threshold, 255, gocv.ThresholdBinary)
+		gocv.Threshold(gray, &output, 0, 255, gocv.ThresholdBinary|gocv.ThresholdOtsu)
 	}
 
 	return output, nil
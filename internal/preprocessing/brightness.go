@@ -0,0 +1,50 @@
+package preprocessing
+
+import (
+	"context"
+
+	"gocv.io/x/gocv"
+)
+
+// defaultBrightnessAmount brighten / darken 的默认调整量
+const defaultBrightnessAmount = 30
+
+func init() {
+	amount := map[string]ParamSpec{
+		"amount": {Kind: ParamInt, Min: 0, Max: 255},
+	}
+
+	Register("brighten", amount, func(step Step, cfg Config) (Processor, error) {
+		return NewBrightnessProcessor(step.IntParam("amount", defaultBrightnessAmount)), nil
+	})
+	Register("darken", amount, func(step Step, cfg Config) (Processor, error) {
+		return NewBrightnessProcessor(-step.IntParam("amount", defaultBrightnessAmount)), nil
+	})
+}
+
+// BrightnessProcessor 亮度调整处理器
+type BrightnessProcessor struct {
+	delta int // 亮度增量，负数表示调暗
+}
+
+// NewBrightnessProcessor 创建亮度调整处理器
+func NewBrightnessProcessor(delta int) *BrightnessProcessor {
+	return &BrightnessProcessor{
+		delta: delta,
+	}
+}
+
+// Process 执行亮度调整
+func (p *BrightnessProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
+	output := gocv.NewMat()
+	input.ConvertToWithParams(&output, input.Type(), 1.0, float32(p.delta))
+	return output, nil
+}
+
+// Name 返回处理器名称
+func (p *BrightnessProcessor) Name() string {
+	if p.delta < 0 {
+		return "darken"
+	}
+	return "brighten"
+}
\ No newline at end of file
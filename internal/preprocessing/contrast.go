@@ -0,0 +1,82 @@
+package preprocessing
+
+import (
+	"context"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+func init() {
+	Register("contrast_enhance", map[string]ParamSpec{
+		"clip_limit": {Kind: ParamFloat, Min: 0.1, Max: 40},
+		"tile_size":  {Kind: ParamInt, Min: 1, Max: 64},
+	}, func(step Step, cfg Config) (Processor, error) {
+		return NewContrastProcessor(step.FloatParam("clip_limit", 2.0), step.IntParam("tile_size", 8)), nil
+	})
+}
+
+// ContrastProcessor 对比度增强处理器 (CLAHE，对比度受限自适应直方图均衡化)
+type ContrastProcessor struct {
+	clipLimit float64
+	tileSize  int
+}
+
+// NewContrastProcessor 创建对比度增强处理器
+func NewContrastProcessor(clipLimit float64, tileSize int) *ContrastProcessor {
+	if clipLimit <= 0 {
+		clipLimit = 2.0
+	}
+	if tileSize <= 0 {
+		tileSize = 8
+	}
+	return &ContrastProcessor{
+		clipLimit: clipLimit,
+		tileSize:  tileSize,
+	}
+}
+
+// Process 执行对比度增强
+func (p *ContrastProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
+	output := gocv.NewMat()
+
+	clahe := gocv.NewCLAHEWithParams(p.clipLimit, image.Pt(p.tileSize, p.tileSize))
+	defer clahe.Close()
+
+	if input.Channels() == 1 {
+		clahe.Apply(input, &output)
+		return output, nil
+	}
+
+	// 转换到 LAB 色彩空间
+	lab := gocv.NewMat()
+	defer lab.Close()
+	gocv.CvtColor(input, &lab, gocv.ColorBGRToLab)
+
+	// 分离通道
+	channels := gocv.Split(lab)
+	defer func() {
+		for _, ch := range channels {
+			ch.Close()
+		}
+	}()
+
+	// 只对 L 通道应用 CLAHE
+	lEnhanced := gocv.NewMat()
+	defer lEnhanced.Close()
+	clahe.Apply(channels[0], &lEnhanced)
+
+	// 合并通道
+	lEnhanced.CopyTo(&channels[0])
+	gocv.Merge(channels, &lab)
+
+	// 转换回 BGR
+	gocv.CvtColor(lab, &output, gocv.ColorLabToBGR)
+
+	return output, nil
+}
+
+// Name 返回处理器名称
+func (p *ContrastProcessor) Name() string {
+	return "contrast_enhance"
+}
\ No newline at end of file
@@ -2,38 +2,30 @@ package preprocessing
 
 import (
 	"context"
-	"image"
 
 	"gocv.io/x/gocv"
 )
 
-// DenoiseMethod 去噪方法
-type DenoiseMethod string
-
-const (
-	DenoiseMedian     DenoiseMethod = "median"
-	DenoiseBilateral  DenoiseMethod = "bilateral"
-	DenoiseFastNl     DenoiseMethod = "fastNlMeans"
-)
+func init() {
+	Register("denoise", map[string]ParamSpec{
+		"strength": {Kind: ParamInt, Min: 1, Max: 30}, // 滤波强度 h
+	}, func(step Step, cfg Config) (Processor, error) {
+		return NewDenoiseProcessor(step.IntParam("strength", 0)), nil
+	})
+}
 
-// DenoiseProcessor 去噪处理器
+// DenoiseProcessor 去噪处理器 (非局部均值去噪)
 type DenoiseProcessor struct {
-	method     DenoiseMethod
-	kernelSize int
+	strength int // 滤波强度 h，0 表示使用 OpenCV 默认值
 }
 
 // NewDenoiseProcessor 创建去噪处理器
-func NewDenoiseProcessor(method DenoiseMethod, kernelSize int) *DenoiseProcessor {
-	if kernelSize == 0 {
-		kernelSize = 5
-	}
-	// 确保 kernel size 是奇数
-	if kernelSize%2 == 0 {
-		kernelSize++
+func NewDenoiseProcessor(strength int) *DenoiseProcessor {
+	if strength < 0 {
+		strength = 0
 	}
 	return &DenoiseProcessor{
-		method:     method,
-		kernelSize: kernelSize,
+		strength: strength,
 	}
 }
 
@@ -41,19 +33,15 @@ func NewDenoiseProcessor(method DenoiseMethod, kernelSize int) *DenoiseProcessor
 func (p *DenoiseProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
 	output := gocv.NewMat()
 
-	switch p.method {
-	case DenoiseMedian:
-		gocv.MedianBlur(input, &output, p.kernelSize)
-	case DenoiseBilateral:
-		gocv.BilateralFilter(input, &output, p.kernelSize, 75, 75)
-	case DenoiseFastNl:
-		if input.Channels() == 1 {
-			gocv.FastNlMeansDenoising(input, &output)
-		} else {
-			gocv.FastNlMeansDenoisingColored(input, &output)
-		}
+	switch {
+	case p.strength > 0 && input.Channels() == 1:
+		gocv.FastNlMeansDenoisingWithParams(input, &output, float32(p.strength), 7, 21)
+	case p.strength > 0:
+		gocv.FastNlMeansDenoisingColoredWithParams(input, &output, float32(p.strength), float32(p.strength), 7, 21)
+	case input.Channels() == 1:
+		gocv.FastNlMeansDenoising(input, &output)
 	default:
-		gocv.MedianBlur(input, &output, p.kernelSize)
+		gocv.FastNlMeansDenoisingColored(input, &output)
 	}
 
 	return output, nil
@@ -5,37 +5,50 @@ import (
 	"image"
 	"math"
 
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
 	"gocv.io/x/gocv"
 )
 
+// minSkewAngle 小于该角度 (度) 的倾斜不校正
+const minSkewAngle = 0.5
+
+func init() {
+	Register("deskew", map[string]ParamSpec{
+		"angle_limit": {Kind: ParamFloat, Min: 0, Max: 45},
+	}, func(step Step, cfg Config) (Processor, error) {
+		return NewDeskewProcessor(step.FloatParam("angle_limit", cfg.DeskewAngleLimit)), nil
+	})
+}
+
 // DeskewProcessor 倾斜校正处理器
 type DeskewProcessor struct {
-	angleThreshold float64
+	angleLimit float64 // 可校正的最大角度 (度)，超过时不校正
 }
 
 // NewDeskewProcessor 创建倾斜校正处理器
-func NewDeskewProcessor(angleThreshold float64) *DeskewProcessor {
-	if angleThreshold == 0 {
-		angleThreshold = 0.5
-	}
+func NewDeskewProcessor(angleLimit float64) *DeskewProcessor {
 	return &DeskewProcessor{
-		angleThreshold: angleThreshold,
+		angleLimit: angleLimit,
 	}
 }
 
 // Process 执行倾斜校正
 func (p *DeskewProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
+	gray := toGray(input)
+	defer gray.Close()
+
 	// 检测倾斜角度
-	angle := p.detectSkewAngle(input)
+	angle := CalculateSkewAngle(gray)
 
-	// 如果角度小于阈值，不需要校正
-	if math.Abs(angle) < p.angleThreshold {
-		return input.Clone(), nil
+	logger.Debug("Detected skew angle", zap.Float64("angle", angle))
+
+	// 如果角度在限制范围内，进行校正
+	if math.Abs(angle) > minSkewAngle && math.Abs(angle) < p.angleLimit {
+		return rotateImage(input, angle), nil
 	}
 
-	// 旋转图像
-	output := p.rotateImage(input, angle)
-	return output, nil
+	return input.Clone(), nil
 }
 
 // Name 返回处理器名称
@@ -43,23 +56,12 @@ func (p *DeskewProcessor) Name() string {
 	return "deskew"
 }
 
-// detectSkewAngle 检测倾斜角度
-func (p *DeskewProcessor) detectSkewAngle(img gocv.Mat) float64 {
-	// 简化实现：返回 0
-	// 完整实现需要使用霍夫变换检测直线并计算角度
-	return 0
-}
-
-// rotateImage 旋转图像
-func (p *DeskewProcessor) rotateImage(img gocv.Mat, angle float64) gocv.Mat {
-	// 获取图像中心
+// rotateImage 绕中心旋转图像
+func rotateImage(img gocv.Mat, angle float64) gocv.Mat {
 	center := image.Pt(img.Cols()/2, img.Rows()/2)
-
-	// 获取旋转矩阵
 	rotationMatrix := gocv.GetRotationMatrix2D(center, angle, 1.0)
 	defer rotationMatrix.Close()
 
-	// 执行旋转
 	output := gocv.NewMat()
 	gocv.WarpAffine(img, &output, rotationMatrix, image.Pt(img.Cols(), img.Rows()))
 
@@ -6,6 +6,12 @@ import (
 	"gocv.io/x/gocv"
 )
 
+func init() {
+	Register("grayscale", nil, func(step Step, cfg Config) (Processor, error) {
+		return NewGrayscaleProcessor(), nil
+	})
+}
+
 // GrayscaleProcessor 灰度化处理器
 type GrayscaleProcessor struct{}
 
@@ -30,4 +36,15 @@ func (p *GrayscaleProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.
 // Name 返回处理器名称
 func (p *GrayscaleProcessor) Name() string {
 	return "grayscale"
+}
+
+// toGray 返回图像的灰度副本 (由调用方释放)
+func toGray(img gocv.Mat) gocv.Mat {
+	gray := gocv.NewMat()
+	if img.Channels() > 1 {
+		gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
+	} else {
+		img.CopyTo(&gray)
+	}
+	return gray
 }
\ No newline at end of file
@@ -3,104 +3,126 @@ package preprocessing
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
 	"gocv.io/x/gocv"
-	"github.com/ricardo/mcp-ocr-server/internal/config"
 )
 
-// Processor 预处理器接口
+// Processor 预处理步骤处理器
+// Process 不修改也不释放输入图像，返回的新图像由调用方释放
 type Processor interface {
 	Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error)
 	Name() string
 }
 
-// Pipeline 智能预处理管道
-type Pipeline struct {
-	analyzer   *ImageAnalyzer
-	config     config.PreprocessingConfig
-	processors map[string]Processor
+// ProcessorFactory 根据步骤创建处理器
+// 步骤参数已按注册的定义验证，未指定的参数由工厂使用 cfg 中的配置或默认值
+type ProcessorFactory func(step Step, cfg Config) (Processor, error)
+
+// registration 已注册的预处理步骤
+type registration struct {
+	params  map[string]ParamSpec
+	factory ProcessorFactory
 }
 
-// NewPipeline 创建新的预处理管道
-func NewPipeline(cfg config.PreprocessingConfig) *Pipeline {
-	pipeline := &Pipeline{
-		analyzer:   NewImageAnalyzer(),
-		config:     cfg,
-		processors: make(map[string]Processor),
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+// Register 注册预处理步骤，注册后可在 pipeline 参数和 profiles 配置中按名称使用
+// 应在 init 中调用 (配置中的 profiles 在启动时验证)；名称为空、工厂为 nil 或名称重复时 panic
+func Register(name string, params map[string]ParamSpec, factory ProcessorFactory) {
+	if name == "" {
+		panic("preprocessing: Register step name is empty")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("preprocessing: Register factory is nil for step %q", name))
 	}
 
-	// 注册所有处理器
-	pipeline.processors["grayscale"] = NewGrayscaleProcessor()
-	pipeline.processors["binarization"] = NewBinarizationProcessor(MethodOtsu, 0)
-	pipeline.processors["denoise"] = NewDenoiseProcessor(DenoiseMedian, 5)
-	pipeline.processors["deskew"] = NewDeskewProcessor(cfg.QualityThresholds.SkewAngleThreshold)
+	registryMu.Lock()
+	defer registryMu.Unlock()
 
-	return pipeline
-}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("preprocessing: Register called twice for step %q", name))
+	}
+
+	specs := make(map[string]ParamSpec, len(params))
+	for key, spec := range params {
+		specs[key] = spec
+	}
 
-// Process 执行智能预处理
-func (p *Pipeline) Process(ctx context.Context, img gocv.Mat) (gocv.Mat, []string, *AnalysisResult, error) {
-	if img.Empty() {
-		return gocv.Mat{}, nil, nil, fmt.Errorf("input image is empty")
+	registry[name] = registration{
+		params:  specs,
+		factory: factory,
 	}
+}
 
-	// 1. 分析图像质量
-	analysis := p.analyzer.Analyze(img)
+// lookup 查找已注册的预处理步骤
+func lookup(name string) (registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 
-	// 2. 根据分析结果决定处理步骤
-	steps := p.determineSteps(analysis)
+	reg, ok := registry[name]
+	return reg, ok
+}
+
+// StepNames 已注册的预处理步骤名称 (按名称排序)
+func StepNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProcessor 按步骤创建处理器
+func NewProcessor(step Step, cfg Config) (Processor, error) {
+	reg, ok := lookup(step.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown step %q (available: %s)", step.Name, strings.Join(StepNames(), ", "))
+	}
+	return reg.factory(step, cfg)
+}
 
-	// 3. 执行处理步骤
+// runPipeline 依次执行预处理步骤，返回的图像由调用方释放
+// 每个步骤开始前检查 ctx，取消或超时时返回 ctx.Err()；trace 不为 nil 时记录各步骤
+func runPipeline(ctx context.Context, img gocv.Mat, steps []Step, cfg Config, trace *Trace) (gocv.Mat, error) {
 	result := img.Clone()
-	appliedSteps := []string{}
 
 	for _, step := range steps {
-		processor, ok := p.processors[step]
-		if !ok {
-			continue
+		if err := ctx.Err(); err != nil {
+			result.Close()
+			return gocv.Mat{}, err
 		}
 
-		// 执行处理
-		processed, err := processor.Process(ctx, result)
+		stepStart := time.Now()
+
+		processor, err := NewProcessor(step, cfg)
 		if err != nil {
 			result.Close()
-			return gocv.Mat{}, appliedSteps, analysis, fmt.Errorf("failed to process %s: %w", step, err)
+			return gocv.Mat{}, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, fmt.Sprintf("preprocessing step '%s' failed", step.Name))
 		}
 
-		// 关闭旧的结果
-		if !result.Empty() {
-			result.Close()
+		processed, err := processor.Process(ctx, result)
+		result.Close()
+		if err != nil {
+			return gocv.Mat{}, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, fmt.Sprintf("preprocessing step '%s' failed", step.Name))
 		}
-
 		result = processed
-		appliedSteps = append(appliedSteps, step)
-	}
 
-	return result, appliedSteps, analysis, nil
-}
-
-// determineSteps 根据图像分析结果决定处理步骤
-func (p *Pipeline) determineSteps(analysis *AnalysisResult) []string {
-	steps := []string{}
-
-	// 总是先灰度化
-	steps = append(steps, "grayscale")
-
-	// 根据质量指标决定其他步骤
-	thresholds := p.config.QualityThresholds
-
-	// 如果噪声过高，进行去噪
-	if analysis.NoiseLevel > thresholds.HighNoise {
-		steps = append(steps, "denoise")
-	}
-
-	// 二值化（提高 OCR 准确度）
-	steps = append(steps, "binarization")
-
-	// 如果倾斜明显，进行校正
-	if analysis.SkewAngle > thresholds.SkewAngleThreshold {
-		steps = append(steps, "deskew")
+		if trace != nil {
+			trace.addStep(step, time.Since(stepStart), result)
+		}
 	}
 
-	return steps
+	return result, nil
 }
\ No newline at end of file
@@ -1,9 +1,8 @@
 package preprocessing
 
 import (
-	"fmt"
-	"image"
-	"math"
+	"context"
+	"time"
 
 	"gocv.io/x/gocv"
 	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
@@ -27,10 +26,12 @@ type Config struct {
 	Resize            bool
 	ResizeWidth       int
 	ResizeHeight      int
+	MaxPixels         int64 // 处理后图像的最大像素数 (宽 x 高，0 表示不限制)
 	QualityThresholds struct {
 		Sharpness  float64
 		Contrast   float64
 		Brightness float64
+		MinDPI     float64 // OCR 适用性评估的最低分辨率 (0 表示不检查)
 	}
 }
 
@@ -54,11 +55,50 @@ func NewPreprocessor(config Config) *Preprocessor {
 	}
 }
 
-// Process 处理图像
-func (p *Preprocessor) Process(imageData []byte) ([]byte, error) {
-	if !p.config.Enabled {
+// ProcessOptions 单次预处理选项
+type ProcessOptions struct {
+	Steps       []Step // 显式指定的预处理步骤 (为空时按配置自动选择)
+	AutoMode    *bool  // 未指定步骤时是否根据图像质量选择步骤 (为 nil 时使用配置)
+	Trace       bool   // 是否记录执行过程 (质量分析结果、各步骤及耗时)
+	TraceImages bool   // 记录时是否保存每个步骤输出的 PNG 图像
+}
+
+// Process 处理图像，ctx 取消或超时时在步骤之间中止并返回 ctx.Err()
+func (p *Preprocessor) Process(ctx context.Context, imageData []byte) ([]byte, error) {
+	result, _, err := p.ProcessWithOptions(ctx, imageData, ProcessOptions{})
+	return result, err
+}
+
+// ProcessWithOptions 按选项处理图像，opts.Trace 为 true 时返回执行记录
+// 显式指定步骤时总是执行这些步骤，不受配置中的启用开关影响
+func (p *Preprocessor) ProcessWithOptions(ctx context.Context, imageData []byte, opts ProcessOptions) ([]byte, *Trace, error) {
+	var trace *Trace
+	if opts.Trace {
+		trace = &Trace{withImages: opts.TraceImages}
+	}
+	startTime := time.Now()
+
+	autoMode := p.config.AutoMode
+	if opts.AutoMode != nil {
+		autoMode = *opts.AutoMode
+	}
+
+	result, err := p.process(ctx, imageData, opts.Steps, autoMode, trace)
+	if trace != nil {
+		trace.Duration = time.Since(startTime)
+	}
+
+	return result, trace, err
+}
+
+// process 执行预处理，trace 不为 nil 时记录执行过程
+func (p *Preprocessor) process(ctx context.Context, imageData []byte, steps []Step, autoMode bool, trace *Trace) ([]byte, error) {
+	if !p.config.Enabled && len(steps) == 0 {
 		return imageData, nil
 	}
+	if trace != nil {
+		trace.Enabled = true
+	}
 
 	// 解码图像
 	img, err := gocv.IMDecode(imageData, gocv.IMReadColor)
@@ -77,265 +117,94 @@ func (p *Preprocessor) Process(imageData []byte) ([]byte, error) {
 		zap.Int("channels", img.Channels()),
 	)
 
-	// 自动模式：分析图像质量
-	var pipeline []string
-	if p.config.AutoMode {
-		quality, err := p.analyzer.Analyze(img)
-		if err != nil {
-			logger.Warn("Failed to analyze image quality", zap.Error(err))
-			pipeline = p.getDefaultPipeline()
-		} else {
-			logger.Info("Image quality analysis",
-				zap.Float64("sharpness", quality.Sharpness),
-				zap.Float64("contrast", quality.Contrast),
-				zap.Float64("brightness", quality.Brightness),
-				zap.Bool("needs_preprocessing", quality.NeedsPreprocessing),
-			)
-			pipeline = quality.SuggestedPipeline
-		}
-	} else {
-		pipeline = p.getDefaultPipeline()
+	pipeline := steps
+	if len(pipeline) == 0 {
+		pipeline = p.selectPipeline(img, autoMode, trace)
 	}
 
-	logger.Info("Preprocessing pipeline", zap.Strings("steps", pipeline))
+	logger.Info("Preprocessing pipeline", zap.String("steps", PipelineKey(pipeline)))
 
 	// 执行预处理管道
-	processed := img.Clone()
-	defer processed.Close()
-
-	for _, step := range pipeline {
-		var err error
-		processed, err = p.applyStep(processed, step)
-		if err != nil {
-			return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, fmt.Sprintf("preprocessing step '%s' failed", step))
-		}
+	processed, err := runPipeline(ctx, img, pipeline, p.config, trace)
+	if err != nil {
+		return nil, err
 	}
+	defer processed.Close()
 
 	// 编码为 PNG
-	buf, err := gocv.IMEncode(".png", processed)
+	result, err := encodePNG(processed)
 	if err != nil {
-		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to encode processed image")
+		return nil, err
 	}
 
-	result := buf.GetBytes()
-	buf.Close()
-
 	logger.Debug("Image preprocessing completed", zap.Int("output_size", len(result)))
 
 	return result, nil
 }
 
-// applyStep 应用单个预处理步骤
-func (p *Preprocessor) applyStep(img gocv.Mat, step string) (gocv.Mat, error) {
-	result := gocv.NewMat()
-
-	switch step {
-	case "grayscale":
-		if img.Channels() > 1 {
-			gocv.CvtColor(img, &result, gocv.ColorBGRToGray)
+// selectPipeline 按配置选择预处理步骤: 自动模式根据图像质量分析结果选择，否则使用默认管道
+// 配置中关闭的步骤 (降噪、二值化、倾斜校正、调整大小) 会被跳过
+func (p *Preprocessor) selectPipeline(img gocv.Mat, autoMode bool, trace *Trace) []Step {
+	var names []string
+	if autoMode {
+		quality, err := p.analyzer.Analyze(img)
+		if err != nil {
+			logger.Warn("Failed to analyze image quality", zap.Error(err))
+			names = p.getDefaultPipeline()
 		} else {
-			img.CopyTo(&result)
-		}
-
-	case "denoise":
-		if p.config.Denoise {
-			// 使用 fastNlMeansDenoising
-			if img.Channels() == 1 {
-				gocv.FastNlMeansDenoising(img, &result)
-			} else {
-				gocv.FastNlMeansDenoisingColored(img, &result)
+			logger.Info("Image quality analysis",
+				zap.Float64("sharpness", quality.Sharpness),
+				zap.Float64("contrast", quality.Contrast),
+				zap.Float64("brightness", quality.Brightness),
+				zap.Bool("needs_preprocessing", quality.NeedsPreprocessing),
+			)
+			names = quality.SuggestedPipeline
+			if trace != nil {
+				trace.Quality = quality
 			}
-		} else {
-			img.CopyTo(&result)
-		}
-
-	case "binarization":
-		if p.config.Binarization {
-			result = p.applyBinarization(img)
-		} else {
-			img.CopyTo(&result)
-		}
-
-	case "deskew":
-		if p.config.DeskewCorrection {
-			result = p.applyDeskew(img)
-		} else {
-			img.CopyTo(&result)
-		}
-
-	case "contrast_enhance":
-		result = p.enhanceContrast(img)
-
-	case "brighten":
-		result = p.adjustBrightness(img, 30)
-
-	case "darken":
-		result = p.adjustBrightness(img, -30)
-
-	case "resize":
-		if p.config.Resize {
-			result = p.applyResize(img)
-		} else {
-			img.CopyTo(&result)
 		}
-
-	default:
-		img.CopyTo(&result)
-	}
-
-	// 释放原图像
-	if !img.Empty() {
-		img.Close()
-	}
-
-	return result, nil
-}
-
-// applyBinarization 应用二值化
-func (p *Preprocessor) applyBinarization(img gocv.Mat) gocv.Mat {
-	result := gocv.NewMat()
-
-	// 确保是灰度图
-	gray := gocv.NewMat()
-	defer gray.Close()
-
-	if img.Channels() > 1 {
-		gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
-	} else {
-		img.CopyTo(&gray)
-	}
-
-	switch p.config.BinarizationMode {
-	case "otsu":
-		gocv.Threshold(gray, &result, 0, 255, gocv.ThresholdBinary|gocv.ThresholdOtsu)
-	case "adaptive":
-		gocv.AdaptiveThreshold(
-			gray,
-			&result,
-			255,
-			gocv.AdaptiveThresholdMean,
-			gocv.ThresholdBinary,
-			p.config.AdaptiveBlockSize,
-			p.config.AdaptiveC,
-		)
-	default:
-		gocv.Threshold(gray, &result, 0, 255, gocv.ThresholdBinary|gocv.ThresholdOtsu)
-	}
-
-	return result
-}
-
-// applyDeskew 应用倾斜校正
-func (p *Preprocessor) applyDeskew(img gocv.Mat) gocv.Mat {
-	// 确保是灰度图
-	gray := gocv.NewMat()
-	defer gray.Close()
-
-	if img.Channels() > 1 {
-		gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
 	} else {
-		img.CopyTo(&gray)
+		names = p.getDefaultPipeline()
 	}
 
-	// 计算倾斜角度
-	angle := CalculateSkewAngle(gray)
-
-	logger.Debug("Detected skew angle", zap.Float64("angle", angle))
-
-	// 如果角度在限制范围内，进行校正
-	if math.Abs(angle) > 0.5 && math.Abs(angle) < p.config.DeskewAngleLimit {
-		return p.rotateImage(img, angle)
+	pipeline := make([]Step, 0, len(names))
+	for _, name := range names {
+		if p.stepEnabled(name) {
+			pipeline = append(pipeline, Step{Name: name})
+		}
 	}
-
-	result := gocv.NewMat()
-	img.CopyTo(&result)
-	return result
-}
-
-// rotateImage 旋转图像
-func (p *Preprocessor) rotateImage(img gocv.Mat, angle float64) gocv.Mat {
-	center := image.Point{X: img.Cols() / 2, Y: img.Rows() / 2}
-	rotMatrix := gocv.GetRotationMatrix2D(center, angle, 1.0)
-	defer rotMatrix.Close()
-
-	result := gocv.NewMat()
-	gocv.WarpAffine(img, &result, rotMatrix, image.Point{X: img.Cols(), Y: img.Rows()})
-
-	return result
+	return pipeline
 }
 
-// enhanceContrast 增强对比度
-func (p *Preprocessor) enhanceContrast(img gocv.Mat) gocv.Mat {
-	result := gocv.NewMat()
-
-	// 使用 CLAHE (对比度受限自适应直方图均衡化)
-	clahe := gocv.NewCLAHE()
-	clahe.SetClipLimit(2.0)
-	clahe.SetTilesGridSize(image.Point{X: 8, Y: 8})
-	defer clahe.Close()
-
-	if img.Channels() == 1 {
-		clahe.Apply(img, &result)
-	} else {
-		// 转换到 LAB 色彩空间
-		lab := gocv.NewMat()
-		defer lab.Close()
-		gocv.CvtColor(img, &lab, gocv.ColorBGRToLab)
-
-		// 分离通道
-		channels := gocv.Split(lab)
-		defer func() {
-			for _, ch := range channels {
-				ch.Close()
-			}
-		}()
-
-		// 只对 L 通道应用 CLAHE
-		lEnhanced := gocv.NewMat()
-		defer lEnhanced.Close()
-		clahe.Apply(channels[0], &lEnhanced)
-
-		// 合并通道
-		lEnhanced.CopyTo(&channels[0])
-		gocv.Merge(channels, &lab)
-
-		// 转换回 BGR
-		gocv.CvtColor(lab, &result, gocv.ColorLabToBGR)
+// stepEnabled 配置中是否启用了该步骤
+func (p *Preprocessor) stepEnabled(name string) bool {
+	switch name {
+	case "denoise":
+		return p.config.Denoise
+	case "binarization":
+		return p.config.Binarization
+	case "deskew":
+		return p.config.DeskewCorrection
+	case "resize":
+		return p.config.Resize
+	default:
+		return true
 	}
-
-	return result
 }
 
-// adjustBrightness 调整亮度
-func (p *Preprocessor) adjustBrightness(img gocv.Mat, delta int) gocv.Mat {
-	result := gocv.NewMat()
-	img.ConvertTo(&result, -1, 1.0, float64(delta))
-	return result
-}
-
-// applyResize 调整图像大小
-func (p *Preprocessor) applyResize(img gocv.Mat) gocv.Mat {
-	result := gocv.NewMat()
-
-	width := p.config.ResizeWidth
-	height := p.config.ResizeHeight
-
-	// 如果只指定了一个维度，保持宽高比
-	if width > 0 && height == 0 {
-		ratio := float64(width) / float64(img.Cols())
-		height = int(float64(img.Rows()) * ratio)
-	} else if height > 0 && width == 0 {
-		ratio := float64(height) / float64(img.Rows())
-		width = int(float64(img.Cols()) * ratio)
+// encodePNG 将图像编码为 PNG
+func encodePNG(img gocv.Mat) ([]byte, error) {
+	buf, err := gocv.IMEncode(gocv.PNGFileExt, img)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to encode processed image")
 	}
+	defer buf.Close()
 
-	if width > 0 && height > 0 {
-		gocv.Resize(img, &result, image.Point{X: width, Y: height}, 0, 0, gocv.InterpolationLinear)
-	} else {
-		img.CopyTo(&result)
-	}
+	// 复制数据，NativeByteBuffer 关闭后底层内存会被释放
+	data := make([]byte, buf.Len())
+	copy(data, buf.GetBytes())
 
-	return result
+	return data, nil
 }
 
 // getDefaultPipeline 获取默认预处理管道
@@ -363,4 +232,29 @@ func (p *Preprocessor) getDefaultPipeline() []string {
 	}
 
 	return pipeline
+}
+
+// EncodeJPEG 将任意 OpenCV 支持的图像数据转码为 JPEG (用于生成 PDF 页面)
+func EncodeJPEG(imageData []byte, quality int) ([]byte, error) {
+	img, err := gocv.IMDecode(imageData, gocv.IMReadAnyColor)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to decode image")
+	}
+	defer img.Close()
+
+	if img.Empty() {
+		return nil, ocrErrors.New(ocrErrors.ErrPreprocessingFailed, "decoded image is empty")
+	}
+
+	buf, err := gocv.IMEncodeWithParams(gocv.JPEGFileExt, img, []int{gocv.IMWriteJpegQuality, quality})
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to encode JPEG")
+	}
+	defer buf.Close()
+
+	// 复制数据，NativeByteBuffer 关闭后底层内存会被释放
+	data := make([]byte, buf.Len())
+	copy(data, buf.GetBytes())
+
+	return data, nil
 }
\ No newline at end of file
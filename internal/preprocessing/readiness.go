@@ -0,0 +1,171 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ricardo/mcp-ocr-server/internal/input"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"gocv.io/x/gocv"
+)
+
+const (
+	// typicalGlyphHeight 正文字符的典型高度 (英寸)，约为 11pt 字体大小写字母的平均高度，用于估算 DPI
+	typicalGlyphHeight = 0.09
+	// minTextDensity 文本像素占比低于该值时视为没有文本 (空白页或纯图片)
+	minTextDensity = 0.002
+)
+
+// DPI 来源
+const (
+	DPISourceMetadata  = "metadata"  // 文件头声明的分辨率
+	DPISourceEstimated = "estimated" // 根据字符高度估算
+)
+
+// QualityReport 图像 OCR 适用性评估结果
+type QualityReport struct {
+	Width       int           // 宽度 (像素)
+	Height      int           // 高度 (像素)
+	Quality     *ImageQuality // 清晰度、对比度、亮度及建议的预处理步骤
+	SkewAngle   float64       // 倾斜角度 (度)
+	DPI         float64       // 分辨率 (无法确定时为 0)
+	DPISource   string        // 分辨率来源: metadata 或 estimated
+	TextHeight  float64       // 字符高度中位数 (像素，未检测到文本时为 0)
+	TextDensity float64       // 文本像素占比 (0-1)
+	Ready       bool          // 是否适合 OCR (没有 Issues)
+	Issues      []string      // 导致不适合 OCR 的问题 (预处理无法修正，需要重新拍摄或扫描)
+	Warnings    []string      // 可由预处理修正的问题
+}
+
+// AnalyzeQuality 评估图像是否适合 OCR (不执行预处理)
+// 模糊、分辨率过低、倾斜超过校正范围或没有文本时判定为不适合
+func (p *Preprocessor) AnalyzeQuality(imageData []byte) (*QualityReport, error) {
+	img, err := gocv.IMDecode(imageData, gocv.IMReadColor)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to decode image")
+	}
+	defer img.Close()
+
+	if img.Empty() {
+		return nil, ocrErrors.New(ocrErrors.ErrPreprocessingFailed, "decoded image is empty")
+	}
+
+	quality, err := p.analyzer.Analyze(img)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to analyze image quality")
+	}
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
+
+	report := &QualityReport{
+		Width:     img.Cols(),
+		Height:    img.Rows(),
+		Quality:   quality,
+		SkewAngle: CalculateSkewAngle(gray),
+	}
+
+	report.TextHeight, report.TextDensity = measureText(gray)
+
+	if dpi, ok := input.Resolution(imageData); ok {
+		report.DPI = dpi
+		report.DPISource = DPISourceMetadata
+	} else if report.TextHeight > 0 {
+		report.DPI = math.Round(report.TextHeight / typicalGlyphHeight)
+		report.DPISource = DPISourceEstimated
+	}
+
+	p.evaluate(report)
+
+	return report, nil
+}
+
+// evaluate 根据阈值判定是否适合 OCR
+func (p *Preprocessor) evaluate(report *QualityReport) {
+	thresholds := p.config.QualityThresholds
+	quality := report.Quality
+
+	report.Issues = make([]string, 0)
+	report.Warnings = make([]string, 0)
+
+	if report.TextDensity < minTextDensity {
+		report.Issues = append(report.Issues, "no text detected")
+	}
+
+	if quality.Sharpness < thresholds.Sharpness {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("image is blurry (sharpness %.1f < %.1f)", quality.Sharpness, thresholds.Sharpness))
+	}
+
+	if thresholds.MinDPI > 0 && report.DPI > 0 && report.DPI < thresholds.MinDPI {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("resolution too low (%.0f DPI < %.0f DPI)", report.DPI, thresholds.MinDPI))
+	}
+
+	if p.config.DeskewAngleLimit > 0 && math.Abs(report.SkewAngle) > p.config.DeskewAngleLimit {
+		report.Issues = append(report.Issues,
+			fmt.Sprintf("skew angle %.1f° exceeds correction limit %.1f°", report.SkewAngle, p.config.DeskewAngleLimit))
+	}
+
+	if quality.Contrast < thresholds.Contrast {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("low contrast (%.1f < %.1f)", quality.Contrast, thresholds.Contrast))
+	}
+
+	if quality.Brightness < p.analyzer.brightnessMinThreshold {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("image is too dark (brightness %.1f)", quality.Brightness))
+	} else if quality.Brightness > p.analyzer.brightnessMaxThreshold {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("image is too bright (brightness %.1f)", quality.Brightness))
+	}
+
+	if report.DPISource == "" {
+		report.Warnings = append(report.Warnings, "resolution unknown")
+	}
+
+	report.Ready = len(report.Issues) == 0
+}
+
+// measureText 检测类似字符的连通区域，返回字符高度中位数和文本像素占比
+func measureText(gray gocv.Mat) (float64, float64) {
+	// Otsu 二值化并反转，文本为前景
+	binary := gocv.NewMat()
+	defer binary.Close()
+	gocv.Threshold(gray, &binary, 0, 255, gocv.ThresholdBinaryInv|gocv.ThresholdOtsu)
+
+	labels := gocv.NewMat()
+	stats := gocv.NewMat()
+	centroids := gocv.NewMat()
+	defer labels.Close()
+	defer stats.Close()
+	defer centroids.Close()
+
+	count := gocv.ConnectedComponentsWithStats(binary, &labels, &stats, &centroids)
+
+	rows, cols := gray.Rows(), gray.Cols()
+	heights := make([]float64, 0, count)
+	var textArea int
+
+	// 标签 0 为背景
+	for i := 1; i < count; i++ {
+		width := int(stats.GetIntAt(i, int(gocv.CC_STAT_WIDTH)))
+		height := int(stats.GetIntAt(i, int(gocv.CC_STAT_HEIGHT)))
+		area := int(stats.GetIntAt(i, int(gocv.CC_STAT_AREA)))
+
+		// 过滤噪点、表格线和大块图片区域
+		if height < 4 || area < 10 || height > rows/4 || width > cols/4 || width > height*8 {
+			continue
+		}
+
+		heights = append(heights, float64(height))
+		textArea += area
+	}
+
+	if len(heights) == 0 {
+		return 0, 0
+	}
+
+	sort.Float64s(heights)
+	return heights[len(heights)/2], float64(textArea) / float64(rows*cols)
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package preprocessing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// ErrOutputTooLarge 调整大小的目标像素数超过 Config.MaxPixels
+var ErrOutputTooLarge = errors.New("resize target exceeds max pixels")
+
+func init() {
+	Register("resize", map[string]ParamSpec{
+		"width":  {Kind: ParamInt, Min: 0, Max: 20000},
+		"height": {Kind: ParamInt, Min: 0, Max: 20000},
+	}, func(step Step, cfg Config) (Processor, error) {
+		return NewResizeProcessor(step.IntParam("width", cfg.ResizeWidth), step.IntParam("height", cfg.ResizeHeight), cfg.MaxPixels), nil
+	})
+}
+
+// ResizeProcessor 调整大小处理器
+type ResizeProcessor struct {
+	width     int
+	height    int
+	maxPixels int64
+}
+
+// NewResizeProcessor 创建调整大小处理器 (只指定一个维度时保持宽高比，都为 0 时不调整)
+// 目标尺寸的像素数超过 maxPixels 时返回 ErrOutputTooLarge (0 表示不限制)
+func NewResizeProcessor(width, height int, maxPixels int64) *ResizeProcessor {
+	return &ResizeProcessor{
+		width:     width,
+		height:    height,
+		maxPixels: maxPixels,
+	}
+}
+
+// Process 执行调整大小
+func (p *ResizeProcessor) Process(ctx context.Context, input gocv.Mat) (gocv.Mat, error) {
+	width, height := p.width, p.height
+
+	// 如果只指定了一个维度，保持宽高比
+	if width > 0 && height == 0 {
+		ratio := float64(width) / float64(input.Cols())
+		height = int(float64(input.Rows()) * ratio)
+	} else if height > 0 && width == 0 {
+		ratio := float64(height) / float64(input.Rows())
+		width = int(float64(input.Cols()) * ratio)
+	}
+
+	if width <= 0 || height <= 0 {
+		return input.Clone(), nil
+	}
+
+	// 输入检查只限制原始图像，放大后的图像同样不能超过像素数上限
+	if p.maxPixels > 0 && int64(width)*int64(height) > p.maxPixels {
+		return gocv.Mat{}, fmt.Errorf("%w: %dx%d > %d", ErrOutputTooLarge, width, height, p.maxPixels)
+	}
+
+	output := gocv.NewMat()
+	gocv.Resize(input, &output, image.Pt(width, height), 0, 0, gocv.InterpolationLinear)
+
+	return output, nil
+}
+
+// Name 返回处理器名称
+func (p *ResizeProcessor) Name() string {
+	return "resize"
+}
\ No newline at end of file
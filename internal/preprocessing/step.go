@@ -0,0 +1,159 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Step 预处理步骤及其参数 (未指定的参数使用配置或默认值)
+// 步骤名称和参数定义见 Register
+type Step struct {
+	Name   string
+	Params map[string]interface{} // 参数值为 int、float64 或 string
+}
+
+// ParamKind 参数类型
+type ParamKind int
+
+const (
+	ParamInt ParamKind = iota
+	ParamFloat
+	ParamString
+)
+
+// ParamSpec 步骤参数定义，ParseStep 按定义转换并检查参数取值
+type ParamSpec struct {
+	Kind     ParamKind
+	Min, Max float64  // 数值范围
+	Odd      bool     // 整数参数是否必须为奇数
+	Values   []string // 字符串参数的可选值
+}
+
+// ParseStep 解析并验证步骤定义，如 {"step": "denoise", "strength": 7}
+// 数值参数接受 JSON 数字 (float64) 或 Go 整数
+func ParseStep(spec map[string]interface{}) (Step, error) {
+	name, _ := spec["step"].(string)
+	if name == "" {
+		return Step{}, fmt.Errorf("step name is required")
+	}
+
+	reg, ok := lookup(name)
+	if !ok {
+		return Step{}, fmt.Errorf("unknown step %q (available: %s)", name, strings.Join(StepNames(), ", "))
+	}
+
+	step := Step{Name: name, Params: make(map[string]interface{})}
+	for key, raw := range spec {
+		if key == "step" {
+			continue
+		}
+
+		param, ok := reg.params[key]
+		if !ok {
+			return Step{}, fmt.Errorf("step %q does not accept parameter %q", name, key)
+		}
+
+		value, err := param.parse(raw)
+		if err != nil {
+			return Step{}, fmt.Errorf("step %q parameter %q: %w", name, key, err)
+		}
+		step.Params[key] = value
+	}
+
+	return step, nil
+}
+
+// parse 按参数定义转换并检查取值
+func (s ParamSpec) parse(raw interface{}) (interface{}, error) {
+	if s.Kind == ParamString {
+		value, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		for _, allowed := range s.Values {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(s.Values, ", "))
+	}
+
+	var value float64
+	switch v := raw.(type) {
+	case float64:
+		value = v
+	case int:
+		value = float64(v)
+	default:
+		return nil, fmt.Errorf("must be a number")
+	}
+
+	if math.IsNaN(value) || value < s.Min || value > s.Max {
+		return nil, fmt.Errorf("must be between %g and %g", s.Min, s.Max)
+	}
+
+	if s.Kind == ParamInt {
+		if value != math.Trunc(value) {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		if s.Odd && int(value)%2 == 0 {
+			return nil, fmt.Errorf("must be odd")
+		}
+		return int(value), nil
+	}
+	return value, nil
+}
+
+// IntParam 获取整数参数，未指定时返回 def
+func (s Step) IntParam(name string, def int) int {
+	if value, ok := s.Params[name].(int); ok {
+		return value
+	}
+	return def
+}
+
+// FloatParam 获取浮点参数，未指定时返回 def
+func (s Step) FloatParam(name string, def float64) float64 {
+	if value, ok := s.Params[name].(float64); ok {
+		return value
+	}
+	return def
+}
+
+// StringParam 获取字符串参数，未指定时返回 def
+func (s Step) StringParam(name string, def string) string {
+	if value, ok := s.Params[name].(string); ok {
+		return value
+	}
+	return def
+}
+
+// Key 步骤的规范表示 (参数按名称排序)，用于缓存键，如 "denoise(strength=7)"
+func (s Step) Key() string {
+	if len(s.Params) == 0 {
+		return s.Name
+	}
+
+	keys := make([]string, 0, len(s.Params))
+	for key := range s.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, s.Params[key]))
+	}
+	return fmt.Sprintf("%s(%s)", s.Name, strings.Join(parts, ","))
+}
+
+// PipelineKey 预处理管道的规范表示
+func PipelineKey(steps []Step) string {
+	keys := make([]string, 0, len(steps))
+	for _, step := range steps {
+		keys = append(keys, step.Key())
+	}
+	return strings.Join(keys, "|")
+}
\ No newline at end of file
@@ -0,0 +1,47 @@
+package preprocessing
+
+import (
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+	"gocv.io/x/gocv"
+)
+
+// StepTrace 单个预处理步骤的执行记录
+type StepTrace struct {
+	Name     string                 // 步骤名称
+	Params   map[string]interface{} // 显式指定的步骤参数
+	Duration time.Duration          // 耗时
+	Image    []byte                 // 步骤输出的 PNG 图像 (仅在要求保存中间图像时)
+}
+
+// Trace 预处理执行记录，用于调试
+type Trace struct {
+	Enabled  bool          // 是否执行了预处理 (未启用时原样返回图像)
+	Quality  *ImageQuality // 自动模式下的质量分析结果
+	Steps    []StepTrace   // 实际执行的步骤
+	Duration time.Duration // 总耗时
+
+	withImages bool
+}
+
+// addStep 记录一个步骤，需要时编码步骤输出图像
+func (t *Trace) addStep(step Step, duration time.Duration, img gocv.Mat) {
+	stepTrace := StepTrace{
+		Name:     step.Name,
+		Params:   step.Params,
+		Duration: duration,
+	}
+
+	if t.withImages {
+		data, err := encodePNG(img)
+		if err != nil {
+			logger.Warn("Failed to encode intermediate image", zap.String("step", step.Name), zap.Error(err))
+		} else {
+			stepTrace.Image = data
+		}
+	}
+
+	t.Steps = append(t.Steps, stepTrace)
+}
\ No newline at end of file
@@ -0,0 +1,149 @@
+package results
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound 结果不存在或已过期
+	ErrNotFound = errors.New("result not found")
+	// ErrTooLarge 单个结果超过总大小上限，不保存
+	ErrTooLarge = errors.New("result exceeds store size limit")
+)
+
+// Entry 保存的识别结果
+type Entry struct {
+	ID        string
+	CreatedAt time.Time
+	Value     interface{} // 结果 (由调用方定义)
+	Size      int64       // 占用的字节数 (用于总大小限制)
+}
+
+// Store 识别结果存储 (内存，按条目数、总大小和 TTL 淘汰最早的结果)
+type Store struct {
+	entries  map[string]*list.Element
+	order    *list.List // 按创建时间排序，最早的在前
+	maxItems int
+	maxBytes int64
+	ttl      time.Duration
+	bytes    int64
+	mu       sync.Mutex
+}
+
+// NewStore 创建结果存储
+// maxItems 为最多保留的结果数，maxBytes 为总大小上限 (0 表示不限制)，ttl 为保留时间 (0 表示不过期)
+func NewStore(maxItems int, maxBytes int64, ttl time.Duration) *Store {
+	return &Store{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		maxItems: maxItems,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+	}
+}
+
+// Put 保存结果并返回随机生成的 ID，单个结果超过总大小上限时返回 ErrTooLarge
+func (s *Store) Put(value interface{}, size int64) (string, error) {
+	// 否则会淘汰其他所有结果并一直占用超出上限的内存
+	if s.maxBytes > 0 && size > s.maxBytes {
+		return "", ErrTooLarge
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked()
+
+	entry := &Entry{
+		ID:        id,
+		CreatedAt: time.Now(),
+		Value:     value,
+		Size:      size,
+	}
+	s.entries[id] = s.order.PushBack(entry)
+	s.bytes += size
+
+	// 超出限制时淘汰最早的结果
+	for s.order.Len() > 0 && ((s.maxItems > 0 && s.order.Len() > s.maxItems) || (s.maxBytes > 0 && s.bytes > s.maxBytes)) {
+		s.removeLocked(s.order.Front())
+	}
+
+	return id, nil
+}
+
+// Get 获取结果
+func (s *Store) Get(id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked()
+
+	elem, ok := s.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return elem.Value.(*Entry), nil
+}
+
+// List 列出全部结果 (最新的在前)
+func (s *Store) List() []*Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked()
+
+	entries := make([]*Entry, 0, s.order.Len())
+	for elem := s.order.Back(); elem != nil; elem = elem.Prev() {
+		entries = append(entries, elem.Value.(*Entry))
+	}
+	return entries
+}
+
+// Len 当前保存的结果数
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// cleanupLocked 删除过期结果 (调用方需持有锁)
+func (s *Store) cleanupLocked() {
+	if s.ttl <= 0 {
+		return
+	}
+
+	deadline := time.Now().Add(-s.ttl)
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if elem.Value.(*Entry).CreatedAt.After(deadline) {
+			return
+		}
+		s.removeLocked(elem)
+	}
+}
+
+// removeLocked 删除结果 (调用方需持有锁)
+func (s *Store) removeLocked(elem *list.Element) {
+	entry := s.order.Remove(elem).(*Entry)
+	delete(s.entries, entry.ID)
+	s.bytes -= entry.Size
+}
+
+// newID 生成随机结果 ID
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate result id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
\ No newline at end of file
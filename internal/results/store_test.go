@@ -0,0 +1,75 @@
+package results
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_PutGet(t *testing.T) {
+	store := NewStore(10, 0, time.Minute)
+
+	id, err := store.Put("hello", 5)
+	if err != nil {
+		t.Fatalf("Failed to put result: %v", err)
+	}
+
+	entry, err := store.Get(id)
+	if err != nil {
+		t.Fatalf("Failed to get result: %v", err)
+	}
+	if entry.Value != "hello" || entry.ID != id {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_Eviction(t *testing.T) {
+	// 按条目数淘汰
+	store := NewStore(2, 0, 0)
+	first, _ := store.Put(1, 0)
+	store.Put(2, 0)
+	third, _ := store.Put(3, 0)
+
+	if _, err := store.Get(first); !errors.Is(err, ErrNotFound) {
+		t.Error("Expected oldest result to be evicted")
+	}
+
+	list := store.List()
+	if len(list) != 2 || list[0].ID != third {
+		t.Errorf("Expected newest result first, got %+v", list)
+	}
+
+	// 按总大小淘汰，超过上限的单个结果不保存
+	store = NewStore(0, 100, 0)
+	store.Put("a", 60)
+	store.Put("b", 30)
+	store.Put("c", 30)
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 results within byte budget, got %d", store.Len())
+	}
+
+	if _, err := store.Put("large", 500); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected existing results to be kept, got %d", store.Len())
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	store := NewStore(10, 0, time.Millisecond*10)
+
+	id, _ := store.Put("x", 1)
+	time.Sleep(time.Millisecond * 20)
+
+	if _, err := store.Get(id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired result to be removed, got %v", err)
+	}
+	if store.Len() != 0 {
+		t.Errorf("Expected empty store, got %d", store.Len())
+	}
+}
\ No newline at end of file
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/config"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Principal 已认证的调用方
+type Principal struct {
+	Name  string          // 调用方名称，按凭证类型区分: "key:<API Key 名称>" 或 "token:<kid>:<sub>"
+	tools map[string]bool // 允许调用的工具 (nil 表示全部)
+}
+
+// Allows 判断调用方是否允许调用指定工具
+func (p *Principal) Allows(tool string) bool {
+	if p == nil || p.tools == nil {
+		return true
+	}
+	return p.tools[tool]
+}
+
+// TokenClaims HMAC 签名 Bearer Token 的声明
+type TokenClaims struct {
+	KeyID   string   `json:"kid"`             // 签名密钥 ID
+	Subject string   `json:"sub"`             // 调用方名称
+	Expires int64    `json:"exp,omitempty"`   // 过期时间 (Unix 秒，0 表示不过期)
+	Tools   []string `json:"tools,omitempty"` // 进一步限制可调用的工具 (不能超出密钥的允许范围)
+}
+
+// apiKeyEntry 静态 API Key
+type apiKeyEntry struct {
+	name  string
+	hash  [sha256.Size]byte
+	tools map[string]bool
+}
+
+// hmacKeyEntry HMAC 签名密钥
+type hmacKeyEntry struct {
+	secret []byte
+	tools  map[string]bool
+}
+
+// Authenticator 校验 HTTP 请求中的 API Key 或 HMAC 签名 Bearer Token
+type Authenticator struct {
+	apiKeys  []apiKeyEntry
+	hmacKeys map[string]hmacKeyEntry
+	now      func() time.Time
+}
+
+// NewAuthenticator 根据配置创建认证器
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	auth := &Authenticator{
+		hmacKeys: make(map[string]hmacKeyEntry),
+		now:      time.Now,
+	}
+
+	for _, key := range cfg.APIKeys {
+		auth.apiKeys = append(auth.apiKeys, apiKeyEntry{
+			name:  key.Name,
+			hash:  sha256.Sum256([]byte(key.Key)),
+			tools: toolSet(key.Tools),
+		})
+	}
+
+	for _, key := range cfg.HMACKeys {
+		auth.hmacKeys[key.ID] = hmacKeyEntry{
+			secret: []byte(key.Secret),
+			tools:  toolSet(key.Tools),
+		}
+	}
+
+	return auth
+}
+
+// Authenticate 校验请求凭证，支持 "Authorization: Bearer <token>" 和 "X-API-Key: <key>"
+// Bearer 凭证既可以是静态 API Key，也可以是 HMAC 签名 Token
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	credential := r.Header.Get("X-API-Key")
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "unsupported authorization scheme")
+		}
+		credential = strings.TrimSpace(token)
+	}
+
+	if credential == "" {
+		return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "missing credentials")
+	}
+
+	if principal := a.authenticateAPIKey(credential); principal != nil {
+		return principal, nil
+	}
+
+	if strings.Contains(credential, ".") {
+		return a.authenticateToken(credential)
+	}
+
+	return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "invalid credentials")
+}
+
+// authenticateAPIKey 匹配静态 API Key (比较哈希值，避免时序侧信道)
+func (a *Authenticator) authenticateAPIKey(credential string) *Principal {
+	hash := sha256.Sum256([]byte(credential))
+
+	var matched *apiKeyEntry
+	for i := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], a.apiKeys[i].hash[:]) == 1 && matched == nil {
+			matched = &a.apiKeys[i]
+		}
+	}
+
+	if matched == nil {
+		return nil
+	}
+	return &Principal{Name: "key:" + matched.name, tools: matched.tools}
+}
+
+// authenticateToken 校验 HMAC 签名 Token: base64url(claims JSON) + "." + base64url(HMAC-SHA256)
+func (a *Authenticator) authenticateToken(token string) (*Principal, error) {
+	payload, signature, _ := strings.Cut(token, ".")
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrUnauthorized, "malformed token")
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrUnauthorized, "malformed token")
+	}
+
+	key, ok := a.hmacKeys[claims.KeyID]
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "unknown token key")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, signPayload(key.secret, payload)) {
+		return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "invalid token signature")
+	}
+
+	if claims.Expires > 0 && a.now().Unix() >= claims.Expires {
+		return nil, ocrErrors.New(ocrErrors.ErrUnauthorized, "token expired")
+	}
+
+	// Token 中的工具列表只能缩小密钥的允许范围
+	tools := key.tools
+	if len(claims.Tools) > 0 {
+		tools = make(map[string]bool)
+		for _, tool := range claims.Tools {
+			if key.tools == nil || key.tools[tool] {
+				tools[tool] = true
+			}
+		}
+	}
+
+	// 名称包含签名密钥 ID: 持有任一 HMAC 密钥的调用方都可以任意设置 sub，
+	// 不能冒充 API Key 或其他密钥签发的调用方 (保存的结果和异步任务按名称区分所有者)
+	name := "token:" + claims.KeyID
+	if claims.Subject != "" {
+		name += ":" + claims.Subject
+	}
+
+	return &Principal{Name: name, tools: tools}, nil
+}
+
+// Middleware 认证中间件: 未通过认证的请求返回 401，通过认证的请求在 context 中携带调用方
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			logger.Warn("Unauthorized request",
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.Error(err),
+			)
+			writeUnauthorized(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// SignToken 使用 HMAC 密钥签发 Bearer Token
+func SignToken(secret string, claims TokenClaims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	signature := base64.RawURLEncoding.EncodeToString(signPayload([]byte(secret), payload))
+
+	return payload + "." + signature, nil
+}
+
+// principalKey context 中调用方的键
+type principalKey struct{}
+
+// WithPrincipal 返回携带调用方的 context
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext 获取 context 中的调用方 (未认证时返回 nil)
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// signPayload 计算 HMAC-SHA256 签名
+func signPayload(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// toolSet 将工具列表转换为集合 (空列表表示不限制)
+func toolSet(tools []string) map[string]bool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		set[tool] = true
+	}
+	return set
+}
+
+// writeUnauthorized 返回 401 和结构化错误
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	body := map[string]interface{}{
+		"error": err.Error(),
+		"code":  string(ocrErrors.ErrUnauthorized),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-ocr-server"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(body)
+}
\ No newline at end of file
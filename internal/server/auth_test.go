@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/config"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+func newTestAuthenticator() *Authenticator {
+	return NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Name: "admin", Key: "admin-key"},
+			{Name: "reader", Key: "reader-key", Tools: []string{"ocr_recognize_text"}},
+		},
+		HMACKeys: []config.HMACKey{
+			{ID: "k1", Secret: "secret", Tools: []string{"ocr_recognize_text", "ocr_batch_recognize"}},
+		},
+	})
+}
+
+func authRequest(header, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	return r
+}
+
+func assertUnauthorized(t *testing.T, err error) {
+	t.Helper()
+
+	ocrErr, ok := err.(*ocrErrors.OCRError)
+	if !ok || ocrErr.Code != ocrErrors.ErrUnauthorized {
+		t.Errorf("Expected UNAUTHORIZED error, got %v", err)
+	}
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	auth := newTestAuthenticator()
+
+	principal, err := auth.Authenticate(authRequest("X-API-Key", "admin-key"))
+	if err != nil {
+		t.Fatalf("Failed to authenticate admin key: %v", err)
+	}
+	if principal.Name != "key:admin" || !principal.Allows("ocr_cancel_job") {
+		t.Errorf("Expected admin to be allowed all tools: %+v", principal)
+	}
+
+	principal, err = auth.Authenticate(authRequest("Authorization", "Bearer reader-key"))
+	if err != nil {
+		t.Fatalf("Failed to authenticate reader key: %v", err)
+	}
+	if !principal.Allows("ocr_recognize_text") || principal.Allows("ocr_batch_recognize") {
+		t.Errorf("Unexpected reader allowlist: %+v", principal)
+	}
+
+	_, err = auth.Authenticate(authRequest("X-API-Key", "wrong"))
+	assertUnauthorized(t, err)
+
+	_, err = auth.Authenticate(authRequest("", ""))
+	assertUnauthorized(t, err)
+
+	_, err = auth.Authenticate(authRequest("Authorization", "Basic YWRtaW46YWRtaW4="))
+	assertUnauthorized(t, err)
+}
+
+func TestAuthenticator_HMACToken(t *testing.T) {
+	auth := newTestAuthenticator()
+	now := time.Unix(1700000000, 0)
+	auth.now = func() time.Time { return now }
+
+	token, err := SignToken("secret", TokenClaims{
+		KeyID:   "k1",
+		Subject: "ci",
+		Expires: now.Add(time.Hour).Unix(),
+		Tools:   []string{"ocr_batch_recognize", "ocr_cancel_job"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	principal, err := auth.Authenticate(authRequest("Authorization", "Bearer "+token))
+	if err != nil {
+		t.Fatalf("Failed to authenticate token: %v", err)
+	}
+	if principal.Name != "token:k1:ci" {
+		t.Errorf("Expected principal token:k1:ci, got %s", principal.Name)
+	}
+	// Token 只能缩小密钥的允许范围
+	if !principal.Allows("ocr_batch_recognize") || principal.Allows("ocr_recognize_text") || principal.Allows("ocr_cancel_job") {
+		t.Errorf("Unexpected token allowlist: %+v", principal)
+	}
+
+	// 签名错误
+	forged, _ := SignToken("other", TokenClaims{KeyID: "k1"})
+	_, err = auth.Authenticate(authRequest("Authorization", "Bearer "+forged))
+	assertUnauthorized(t, err)
+
+	// 未知密钥
+	unknown, _ := SignToken("secret", TokenClaims{KeyID: "k2"})
+	_, err = auth.Authenticate(authRequest("Authorization", "Bearer "+unknown))
+	assertUnauthorized(t, err)
+
+	// 已过期
+	expired, _ := SignToken("secret", TokenClaims{KeyID: "k1", Expires: now.Unix()})
+	_, err = auth.Authenticate(authRequest("Authorization", "Bearer "+expired))
+	assertUnauthorized(t, err)
+}
+
+func TestAuthenticator_TokenCannotImpersonateAPIKey(t *testing.T) {
+	auth := newTestAuthenticator()
+
+	keyPrincipal, err := auth.Authenticate(authRequest("X-API-Key", "admin-key"))
+	if err != nil {
+		t.Fatalf("Failed to authenticate admin key: %v", err)
+	}
+
+	// 持有 HMAC 密钥的调用方将 sub 设置为 API Key 的名称
+	for _, subject := range []string{"admin", keyPrincipal.Name} {
+		token, _ := SignToken("secret", TokenClaims{KeyID: "k1", Subject: subject})
+		principal, err := auth.Authenticate(authRequest("Authorization", "Bearer "+token))
+		if err != nil {
+			t.Fatalf("Failed to authenticate token: %v", err)
+		}
+		if principal.Name == keyPrincipal.Name {
+			t.Errorf("Token with sub %q got the API key owner %q", subject, principal.Name)
+		}
+	}
+
+	// 未设置 sub 时使用密钥 ID
+	token, _ := SignToken("secret", TokenClaims{KeyID: "k1"})
+	principal, err := auth.Authenticate(authRequest("Authorization", "Bearer "+token))
+	if err != nil {
+		t.Fatalf("Failed to authenticate token: %v", err)
+	}
+	if principal.Name != "token:k1" {
+		t.Errorf("Expected principal token:k1, got %s", principal.Name)
+	}
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	auth := newTestAuthenticator()
+
+	var got *Principal
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = PrincipalFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, authRequest("X-API-Key", "reader-key"))
+	if recorder.Code != http.StatusOK || got == nil || got.Name != "key:reader" {
+		t.Errorf("Expected reader to pass, got status %d, principal %+v", recorder.Code, got)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, authRequest("X-API-Key", "wrong"))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %d", recorder.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body["code"] != string(ocrErrors.ErrUnauthorized) {
+		t.Errorf("Expected UNAUTHORIZED code, got %v", body["code"])
+	}
+}
\ No newline at end of file
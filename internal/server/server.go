@@ -2,11 +2,16 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ricardo/mcp-ocr-server/internal/config"
 	"github.com/ricardo/mcp-ocr-server/internal/tools"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
 	"github.com/ricardo/mcp-ocr-server/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -16,6 +21,9 @@ type Server struct {
 	mcpServer   *mcp.Server
 	toolHandler *tools.Handler
 	config      *config.Config
+	httpServer  *http.Server
+	auth        *Authenticator
+	mu          sync.Mutex
 }
 
 // New 创建 MCP Server
@@ -26,20 +34,17 @@ func New(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to create tool handler: %w", err)
 	}
 
-	// 创建 MCP Server
-	mcpServer := mcp.NewServer(&mcp.ServerOptions{
-		Name:    cfg.Server.Name,
-		Version: cfg.Server.Version,
-	})
-
 	server := &Server{
-		mcpServer:   mcpServer,
 		toolHandler: toolHandler,
 		config:      cfg,
 	}
 
-	// 注册处理器
-	server.registerHandlers()
+	if cfg.Server.Auth.Enabled {
+		server.auth = NewAuthenticator(cfg.Server.Auth)
+	}
+
+	// 创建 MCP Server (stdio 和未启用认证时使用，不限制工具)
+	server.mcpServer = server.newMCPServer(nil)
 
 	logger.Info("MCP Server created",
 		zap.String("name", cfg.Server.Name),
@@ -49,13 +54,35 @@ func New(cfg *config.Config) (*Server, error) {
 	return server, nil
 }
 
+// newMCPServer 创建 MCP Server 并注册处理器，principal 不为 nil 时只暴露其允许的工具
+func (s *Server) newMCPServer(principal *Principal) *mcp.Server {
+	mcpServer := mcp.NewServer(&mcp.ServerOptions{
+		Name:    s.config.Server.Name,
+		Version: s.config.Server.Version,
+	})
+
+	s.registerHandlers(mcpServer, principal)
+
+	return mcpServer
+}
+
 // registerHandlers 注册处理器
-func (s *Server) registerHandlers() {
+func (s *Server) registerHandlers(mcpServer *mcp.Server, principal *Principal) {
 	// 注册工具列表处理器
-	s.mcpServer.HandleListTools(func(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	mcpServer.HandleListTools(func(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
 		logger.Debug("ListTools called")
 
-		toolSchemas := tools.GetToolSchemas()
+		schemas := tools.GetToolSchemas()
+		if s.config.Security.AdminTools {
+			schemas = append(schemas, tools.GetAdminToolSchemas()...)
+		}
+
+		toolSchemas := make([]mcp.Tool, 0)
+		for _, tool := range schemas {
+			if principal.Allows(tool.Name) {
+				toolSchemas = append(toolSchemas, tool)
+			}
+		}
 
 		return &mcp.ListToolsResult{
 			Tools: toolSchemas,
@@ -63,17 +90,33 @@ func (s *Server) registerHandlers() {
 	})
 
 	// 注册工具调用处理器
-	s.mcpServer.HandleCallTool(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	mcpServer.HandleCallTool(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		logger.Info("CallTool called",
 			zap.String("tool", request.Params.Name),
 		)
 
+		if !principal.Allows(request.Params.Name) {
+			logger.Warn("Tool not allowed",
+				zap.String("tool", request.Params.Name),
+				zap.String("principal", principal.Name),
+			)
+			return tools.ErrorResult(ocrErrors.New(ocrErrors.ErrUnauthorized, "tool not allowed for this credential").
+				WithDetails("tool", request.Params.Name)), nil
+		}
+
 		// 解析参数
 		arguments, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			arguments = make(map[string]interface{})
 		}
 
+		ctx = callerContext(ctx, principal)
+
+		// 客户端提供 progress token 时，通过进度通知报告批量和多页识别的进度
+		if token := request.Params.GetProgressToken(); token != nil && request.Session != nil {
+			ctx = tools.WithProgress(ctx, s.progressNotifier(ctx, request.Session, token))
+		}
+
 		// 调用工具处理器
 		result, err := s.toolHandler.Handle(ctx, request.Params.Name, arguments)
 		if err != nil {
@@ -87,16 +130,141 @@ func (s *Server) registerHandlers() {
 		return result, nil
 	})
 
+	// 注册资源处理器 (识别结果和预处理图像)
+	mcpServer.HandleListResources(func(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
+		logger.Debug("ListResources called")
+
+		return &mcp.ListResourcesResult{
+			Resources: s.toolHandler.ListResources(callerContext(ctx, principal)),
+		}, nil
+	})
+
+	mcpServer.HandleListResourceTemplates(func(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
+		return &mcp.ListResourceTemplatesResult{
+			ResourceTemplates: tools.GetResourceTemplates(),
+		}, nil
+	})
+
+	mcpServer.HandleReadResource(func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		logger.Info("ReadResource called", zap.String("uri", request.Params.URI))
+
+		result, err := s.toolHandler.ReadResource(callerContext(ctx, principal), request.Params.URI)
+		if err != nil {
+			logger.Warn("Failed to read resource",
+				zap.String("uri", request.Params.URI),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		return result, nil
+	})
+
 	logger.Debug("Handlers registered")
 }
 
-// Start 启动服务器
+// callerContext 在 context 中记录已认证的调用方，保存的识别结果按调用方和工具权限隔离
+// principal 为 nil (stdio 或未启用认证) 时不限制
+func callerContext(ctx context.Context, principal *Principal) context.Context {
+	if principal == nil {
+		return ctx
+	}
+	return tools.WithCaller(ctx, principal.Name, principal.Allows)
+}
+
+// progressNotifier 创建发送 MCP 进度通知 (notifications/progress) 的回调
+func (s *Server) progressNotifier(ctx context.Context, session *mcp.ServerSession, token interface{}) tools.ProgressFunc {
+	return func(done, total int, message string) {
+		err := session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      float64(done),
+			Total:         float64(total),
+			Message:       message,
+		})
+		if err != nil {
+			logger.Debug("Failed to send progress notification", zap.Error(err))
+		}
+	}
+}
+
+// Start 启动服务器 (阻塞直到传输层关闭)
 func (s *Server) Start() error {
-	logger.Info("Starting MCP Server...")
+	switch s.config.Server.Transport {
+	case config.TransportHTTP, config.TransportSSE:
+		return s.serveHTTP()
+	default:
+		logger.Info("Starting MCP Server...", zap.String("transport", config.TransportStdio))
+
+		// 使用标准输入输出进行通信
+		if err := s.mcpServer.ServeStdio(); err != nil {
+			return fmt.Errorf("failed to serve stdio: %w", err)
+		}
+		return nil
+	}
+}
+
+// serveHTTP 通过 Streamable HTTP 或 SSE 提供服务，多个客户端可共享同一服务器
+func (s *Server) serveHTTP() error {
+	cfg := s.config.Server
+
+	// 启用认证时每个会话使用只暴露调用方允许工具的 MCP Server
+	getServer := func(r *http.Request) *mcp.Server {
+		if principal := PrincipalFromContext(r.Context()); principal != nil {
+			return s.newMCPServer(principal)
+		}
+		return s.mcpServer
+	}
+
+	var handler http.Handler
+	if cfg.Transport == config.TransportSSE {
+		handler = mcp.NewSSEHandler(getServer)
+	} else {
+		handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	}
+
+	if s.auth != nil {
+		handler = s.auth.Middleware(handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, handler)
+
+	s.mu.Lock()
+	s.httpServer = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	httpServer := s.httpServer
+	s.mu.Unlock()
+
+	logger.Info("Starting MCP Server...",
+		zap.String("transport", cfg.Transport),
+		zap.String("address", cfg.Address),
+		zap.String("path", cfg.Path),
+		zap.Bool("auth", s.auth != nil),
+	)
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve %s: %w", cfg.Transport, err)
+	}
+
+	return nil
+}
+
+// Shutdown 优雅关闭传输层: 停止接受新连接并等待进行中的请求完成，直到 ctx 结束
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	httpServer := s.httpServer
+	s.mu.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
 
-	// 使用标准输入输出进行通信
-	if err := s.mcpServer.ServeStdio(); err != nil {
-		return fmt.Errorf("failed to serve stdio: %w", err)
+	logger.Info("Shutting down HTTP transport...")
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down HTTP transport: %w", err)
 	}
 
 	return nil
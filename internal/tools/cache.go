@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/cache"
+	"github.com/ricardo/mcp-ocr-server/internal/config"
+	"github.com/ricardo/mcp-ocr-server/internal/ocr"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func init() {
+	// 磁盘缓存使用 gob 编码识别结果
+	gob.Register(&ocr.RecognizeResult{})
+	gob.Register(&ocr.DetailedResult{})
+}
+
+// newResultCache 按 performance.cache_backend 创建识别结果缓存
+func newResultCache(cfg config.PerformanceConfig) (cache.Backend, error) {
+	ttl := time.Duration(cfg.CacheTTL) * time.Second
+
+	if !cfg.CacheEnabled || cfg.CacheBackend != cache.BackendDisk {
+		return cache.NewCache(cfg.CacheSize, cfg.CacheMaxBytes, ttl, cfg.CacheEnabled), nil
+	}
+
+	dir := cfg.CacheDir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine cache directory, set performance.cache_dir: %w", err)
+		}
+		dir = filepath.Join(userCacheDir, "mcp-ocr-server")
+	}
+
+	diskCache, err := cache.NewDiskCache(dir, cfg.CacheSize, cfg.CacheMaxBytes, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Using disk result cache", zap.String("dir", dir), zap.Int64("max_bytes", cfg.CacheMaxBytes))
+
+	return diskCache, nil
+}
+
+// coalesce 合并相同缓存键的并发识别请求，只执行一次预处理和 OCR
+// 只合并同一缓存失效代数的请求 (图像失效后的请求不等待失效前开始的识别)
+// 共享的执行不随发起请求取消 (由 fn 自行应用 OCR 超时)，只在所有请求都放弃等待时取消；
+// 等待期间本请求超时或被取消时返回 TIMEOUT 错误
+func (h *Handler) coalesce(ctx context.Context, key string, gen uint64, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	flightKey := fmt.Sprintf("%d/%s", gen, key)
+	value, shared, err := h.inflight.Do(ctx, flightKey, fn)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ocrErrors.Wrap(ctx.Err(), ocrErrors.ErrTimeout, "recognition timed out or was cancelled")
+		}
+		return nil, err
+	}
+
+	if shared {
+		logger.Debug("OCR result shared with concurrent request", zap.String("key", key))
+	}
+	return value, nil
+}
+
+// cacheResult 缓存识别结果，识别开始后 (gen 之后) 该图像被失效或缓存被清空时丢弃结果
+func (h *Handler) cacheResult(key string, gen uint64, result interface{}) {
+	if !h.generation.Set(h.cache, gen, key, result) {
+		logger.Debug("Cache invalidated during recognition, result not cached", zap.String("key", key))
+	}
+}
+
+// handleCacheStats 返回识别结果缓存的统计信息
+func (h *Handler) handleCacheStats(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	stats := h.cache.Stats()
+	stats["in_flight"] = h.inflight.InFlight()
+
+	return h.successResult(stats), nil
+}
+
+// handleCacheInvalidate 删除指定图像的全部缓存结果 (所有语言、预处理和版面层级)
+// 按路径删除时，PDF 和多页 TIFF 的每页结果一并删除
+func (h *Handler) handleCacheInvalidate(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	imagePath := h.getStringArg(args, "image_path", "")
+	imageHash := h.getStringArg(args, "image_hash", "")
+
+	var hashes []string
+	switch {
+	case imagePath != "" && imageHash != "":
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "only one of image_path or image_hash may be given")), nil
+
+	case imagePath != "":
+		imageData, err := h.readImageFile(imagePath)
+		if err != nil {
+			return h.errorResult(err), nil
+		}
+
+		// 拆分 PDF 需要运行外部命令，与识别一样通过 Worker 池执行
+		value, err := h.runTask(ctx, "cache_invalidate", func(ctx context.Context) (interface{}, error) {
+			return h.imageHashes(ctx, imageData)
+		})
+		if err != nil {
+			return h.errorResult(err), nil
+		}
+		hashes = value.([]string)
+
+	case imageHash != "":
+		hash := strings.ToLower(imageHash)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+			return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "image_hash must be a hex-encoded SHA256 hash")), nil
+		}
+		hashes = []string{hash}
+
+	default:
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "image_path or image_hash is required")), nil
+	}
+
+	// 这些图像进行中的识别不再写回失效前的结果 (其他图像的识别不受影响)
+	removed := 0
+	h.generation.Invalidate(hashes, func() {
+		for _, hash := range hashes {
+			removed += h.cache.DeletePrefix(cache.ImagePrefix(hash))
+		}
+	})
+
+	logger.Info("Cache invalidated", zap.Strings("image_hashes", hashes), zap.Int("removed", removed))
+
+	return h.successResult(map[string]interface{}{
+		"image_hashes": hashes,
+		"removed":      removed,
+	}), nil
+}
+
+// imageHashes 输入数据及其各页图像 (PDF、多页 TIFF) 的哈希
+func (h *Handler) imageHashes(ctx context.Context, data []byte) ([]string, error) {
+	hashes := []string{cache.ImageHash(data)}
+
+	info, err := h.inspectInput(data)
+	if err != nil {
+		// 无法识别的输入不会有分页结果，只删除整体数据的结果
+		return hashes, nil
+	}
+
+	pages, err := h.splitPages(ctx, data, info.Format)
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		hashes = append(hashes, cache.ImageHash(page))
+	}
+
+	return hashes, nil
+}
+
+// handleCacheClear 清空识别结果缓存
+func (h *Handler) handleCacheClear(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	removed := 0
+	h.generation.InvalidateAll(func() {
+		removed = h.cache.Size()
+		h.cache.Clear()
+	})
+
+	logger.Info("Cache cleared by tool", zap.Int("removed", removed))
+
+	return h.successResult(map[string]interface{}{
+		"removed": removed,
+	}), nil
+}
\ No newline at end of file
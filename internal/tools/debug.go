@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/preprocessing"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// debugKey context 中调试记录器的键
+type debugKey struct{}
+
+// debugCollector 收集一次调用中各页的预处理记录 (并发安全)
+type debugCollector struct {
+	withImages bool
+	pages      []debugPage
+	mu         sync.Mutex
+}
+
+// debugPage 单页的预处理记录
+type debugPage struct {
+	trace *preprocessing.Trace
+	err   error
+}
+
+// withDebug 返回携带调试记录器的 context，params.Debug 为 false 时返回 nil 记录器
+func withDebug(ctx context.Context, params recognizeParams) (context.Context, *debugCollector) {
+	if !params.Debug {
+		return ctx, nil
+	}
+
+	collector := &debugCollector{withImages: params.DebugImages}
+	return context.WithValue(ctx, debugKey{}, collector), collector
+}
+
+// debugFromContext 获取 context 中的调试记录器
+func debugFromContext(ctx context.Context) *debugCollector {
+	collector, _ := ctx.Value(debugKey{}).(*debugCollector)
+	return collector
+}
+
+// add 记录一页的预处理结果 (按识别顺序，即页码顺序)
+func (c *debugCollector) add(trace *preprocessing.Trace, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages = append(c.pages, debugPage{trace: trace, err: err})
+}
+
+// preprocess 按参数预处理图像，预处理失败时使用原始图像，ctx 取消或超时时返回 TIMEOUT 错误
+// 调整大小的目标超过 ocr.max_pixels 时返回 IMAGE_TOO_LARGE 错误
+// ctx 中有调试记录器时记录质量分析结果、步骤和耗时
+func (h *Handler) preprocess(ctx context.Context, imageData []byte, params recognizeParams) ([]byte, error) {
+	collector := debugFromContext(ctx)
+
+	if !params.Preprocess {
+		if collector != nil {
+			collector.add(&preprocessing.Trace{}, nil)
+		}
+		return imageData, nil
+	}
+
+	opts := preprocessing.ProcessOptions{Steps: params.Pipeline, AutoMode: &params.AutoMode}
+	if collector != nil {
+		opts.Trace = true
+		opts.TraceImages = collector.withImages
+	}
+
+	processedData, trace, err := h.preprocessor.ProcessWithOptions(ctx, imageData, opts)
+	if collector != nil {
+		collector.add(trace, err)
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ocrErrors.Wrap(ctx.Err(), ocrErrors.ErrTimeout, "preprocessing timed out or was cancelled").
+				WithDetails("timeout", h.config.OCR.Timeout)
+		}
+		if errors.Is(err, preprocessing.ErrOutputTooLarge) {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrImageTooLarge, "preprocessing output exceeds max_pixels").
+				WithDetails("max_pixels", h.config.OCR.MaxPixels)
+		}
+		logger.Warn("Preprocessing failed, using original image", zap.Error(err))
+		return imageData, nil
+	}
+	return processedData, nil
+}
+
+// appendTo 将调试信息附加到工具结果: 一个 JSON 文本内容，要求中间图像时每个步骤附加一个 PNG 图像内容
+func (c *debugCollector) appendTo(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if c == nil {
+		return result
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pages := make([]map[string]interface{}, 0, len(c.pages))
+	var images []interface{}
+
+	for i, page := range c.pages {
+		entry := map[string]interface{}{
+			"page":         i + 1,
+			"preprocessed": page.trace.Enabled,
+			"duration":     page.trace.Duration.Seconds(),
+		}
+
+		if quality := page.trace.Quality; quality != nil {
+			entry["quality"] = map[string]interface{}{
+				"sharpness":           quality.Sharpness,
+				"contrast":            quality.Contrast,
+				"brightness":          quality.Brightness,
+				"needs_preprocessing": quality.NeedsPreprocessing,
+				"suggested_pipeline":  quality.SuggestedPipeline,
+			}
+		}
+
+		steps := make([]map[string]interface{}, 0, len(page.trace.Steps))
+		for _, step := range page.trace.Steps {
+			stepEntry := map[string]interface{}{
+				"name":     step.Name,
+				"duration": step.Duration.Seconds(),
+			}
+			if len(step.Params) > 0 {
+				stepEntry["params"] = step.Params
+			}
+			steps = append(steps, stepEntry)
+
+			if len(step.Image) > 0 {
+				images = append(images,
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("page %d, step: %s", i+1, step.Name),
+					},
+					mcp.ImageContent{
+						Type:     "image",
+						Data:     base64.StdEncoding.EncodeToString(step.Image),
+						MIMEType: "image/png",
+					},
+				)
+			}
+		}
+		entry["steps"] = steps
+
+		if page.err != nil {
+			entry["error"] = page.err.Error()
+		}
+
+		pages = append(pages, entry)
+	}
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"debug": map[string]interface{}{
+			"pages": pages,
+		},
+	})
+
+	result.Content = append(result.Content, mcp.TextContent{
+		Type: "text",
+		Text: string(jsonData),
+	})
+	result.Content = append(result.Content, images...)
+
+	return result
+}
\ No newline at end of file
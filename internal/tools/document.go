@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/input"
+	"github.com/ricardo/mcp-ocr-server/internal/ocr"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// pageSeparator 多页文档拼接文本时的分页符 (与 Tesseract 一致)
+const pageSeparator = "\f"
+
+// recognizeInput 识别输入数据: 普通图像返回单个结果，PDF 等多页文档按页识别
+func (h *Handler) recognizeInput(ctx context.Context, data []byte, params recognizeParams) (interface{}, error) {
+	// 检查输入大小、格式和像素数
+	info, err := h.inspectInput(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := h.splitPages(ctx, data, info.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 单页文本结果为 ocr.RecognizeResult (包含 InputFormat)
+	if pages == nil && params.Format == ocr.FormatText {
+		return h.recognizeImage(ctx, data, params)
+	}
+
+	var result map[string]interface{}
+	if pages == nil {
+		result, err = h.recognizeFormatted(ctx, [][]byte{data}, params)
+	} else {
+		result, err = h.recognizeDocument(ctx, pages, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result["input_format"] = string(info.Format)
+	return result, nil
+}
+
+// splitPages 将多页文档 (PDF、多页 TIFF) 拆分为单页图像，单页图像返回 nil
+func (h *Handler) splitPages(ctx context.Context, data []byte, format input.Format) ([][]byte, error) {
+	switch format {
+	case input.FormatPDF:
+		startTime := time.Now()
+		pages, err := h.pdfRasterizer.Rasterize(ctx, data)
+		if errors.Is(err, input.ErrPDFLimit) {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrImageTooLarge, "PDF exceeds limits").
+				WithDetails("max_pages", h.config.OCR.PDF.MaxPages).
+				WithDetails("max_pixels", h.config.OCR.MaxPixels).
+				WithDetails("dpi", h.pdfRasterizer.DPI())
+		}
+		if err != nil {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrPreprocessingFailed, "failed to rasterize PDF")
+		}
+
+		logger.Info("PDF rasterized",
+			zap.Int("pages", len(pages)),
+			zap.Int("dpi", h.pdfRasterizer.DPI()),
+			zap.Duration("duration", time.Since(startTime)),
+		)
+
+		return pages, nil
+
+	case input.FormatTIFF:
+		// OpenCV 解码 TIFF 时只读取第一帧，因此先按 IFD 拆分为单页 TIFF
+		limits := h.config.OCR.TIFF
+		pages, err := input.SplitTIFF(data, limits.MaxPages, limits.MaxBytes)
+		if errors.Is(err, input.ErrTIFFLimit) {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrImageTooLarge, "multi-page TIFF exceeds limits").
+				WithDetails("max_pages", limits.MaxPages).
+				WithDetails("max_bytes", limits.MaxBytes)
+		}
+		if err != nil {
+			// 无法拆分时按单页图像处理，交由解码器读取第一帧
+			logger.Warn("Failed to split TIFF, falling back to first frame", zap.Error(err))
+			return nil, nil
+		}
+		if len(pages) == 1 {
+			return nil, nil
+		}
+
+		logger.Info("Multi-page TIFF split", zap.Int("pages", len(pages)))
+
+		return pages, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// recognizeDocument 逐页识别多页文档，返回每页结果及拼接后的全文
+// 每页独立缓存 (缓存键基于页面图像哈希)，单页失败不影响其他页
+func (h *Handler) recognizeDocument(ctx context.Context, pages [][]byte, params recognizeParams) (map[string]interface{}, error) {
+	if params.Format != ocr.FormatText {
+		return h.recognizeFormatted(ctx, pages, params)
+	}
+
+	startTime := time.Now()
+	progress := newProgressTracker(ctx, len(pages))
+	pageResults := make([]map[string]interface{}, 0, len(pages))
+	texts := make([]string, 0, len(pages))
+	var totalConf float64
+	succeeded := 0
+
+	for i, page := range pages {
+		if ctx.Err() != nil {
+			return nil, ocrErrors.Wrap(ctx.Err(), ocrErrors.ErrTimeout, fmt.Sprintf("document recognition interrupted at page %d", i+1))
+		}
+
+		result, err := h.recognizeImage(ctx, page, params)
+		progress.step(fmt.Sprintf("page %d", i+1))
+		if err != nil {
+			pageResults = append(pageResults, map[string]interface{}{
+				"page":  i + 1,
+				"error": err.Error(),
+			})
+			texts = append(texts, "")
+			continue
+		}
+
+		pageResults = append(pageResults, map[string]interface{}{
+			"page":       i + 1,
+			"text":       result.Text,
+			"confidence": result.Confidence,
+			"duration":   result.Duration.Seconds(),
+		})
+		texts = append(texts, result.Text)
+		totalConf += result.Confidence
+		succeeded++
+	}
+
+	if succeeded == 0 {
+		return nil, ocrErrors.New(ocrErrors.ErrOCREngineFailed, "recognition failed for all pages").
+			WithDetails("pages", pageResults)
+	}
+
+	return map[string]interface{}{
+		"text":       strings.Join(texts, pageSeparator),
+		"confidence": totalConf / float64(succeeded),
+		"language":   params.Language,
+		"pages":      pageResults,
+		"page_count": len(pages),
+		"duration":   time.Since(startTime).Seconds(),
+	}, nil
+}
+
+// recognizeFormatted 识别一页或多页图像并按 hOCR/ALTO/TSV 格式输出
+func (h *Handler) recognizeFormatted(ctx context.Context, pages [][]byte, params recognizeParams) (map[string]interface{}, error) {
+	startTime := time.Now()
+	layoutPages := make([]ocr.LayoutPage, 0, len(pages))
+	texts := make([]string, 0, len(pages))
+	var totalConf float64
+
+	// 单页图像不报告进度
+	progressCtx := ctx
+	if len(pages) == 1 {
+		progressCtx = withoutProgress(ctx)
+	}
+	progress := newProgressTracker(progressCtx, len(pages))
+
+	for i, page := range pages {
+		result, err := h.recognizeLayout(ctx, page, params, ocr.LayoutLevelWord)
+		progress.step(fmt.Sprintf("page %d", i+1))
+		if err != nil {
+			if ocrErr, ok := err.(*ocrErrors.OCRError); ok && len(pages) > 1 {
+				return nil, ocrErr.Clone().WithDetails("page", i+1)
+			}
+			return nil, err
+		}
+
+		layoutPages = append(layoutPages, ocr.LayoutPage{Number: i + 1, Result: result})
+		texts = append(texts, result.Text)
+		totalConf += result.Confidence
+	}
+
+	content, err := ocr.Render(params.Format, layoutPages)
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "failed to render output")
+	}
+
+	result := map[string]interface{}{
+		"format":     string(params.Format),
+		"mime_type":  params.Format.MIMEType(),
+		"content":    content,
+		"text":       strings.Join(texts, pageSeparator),
+		"confidence": totalConf / float64(len(pages)),
+		"language":   params.Language,
+		"duration":   time.Since(startTime).Seconds(),
+	}
+	if len(pages) > 1 {
+		result["page_count"] = len(pages)
+	}
+
+	return result, nil
+}
+
+// batchResultMap 将识别结果转换为批量结果条目
+func batchResultMap(path string, result interface{}) map[string]interface{} {
+	switch r := result.(type) {
+	case *ocr.RecognizeResult:
+		return map[string]interface{}{
+			"path":         path,
+			"text":         r.Text,
+			"confidence":   r.Confidence,
+			"language":     r.Language,
+			"duration":     r.Duration.Seconds(),
+			"input_format": r.InputFormat,
+		}
+	case map[string]interface{}:
+		// 复制结果，避免修改共享的结果 (如异步任务中保存的结果)
+		entry := make(map[string]interface{}, len(r)+1)
+		for k, v := range r {
+			entry[k] = v
+		}
+		entry["path"] = path
+		return entry
+	default:
+		return map[string]interface{}{
+			"path":   path,
+			"result": result,
+		}
+	}
+}
\ No newline at end of file
@@ -1,21 +1,26 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image/jpeg"
 	"os"
-	"path/filepath"
-	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ricardo/mcp-ocr-server/internal/cache"
 	"github.com/ricardo/mcp-ocr-server/internal/config"
+	"github.com/ricardo/mcp-ocr-server/internal/input"
+	"github.com/ricardo/mcp-ocr-server/internal/jobs"
 	"github.com/ricardo/mcp-ocr-server/internal/ocr"
+	"github.com/ricardo/mcp-ocr-server/internal/pdf"
 	"github.com/ricardo/mcp-ocr-server/internal/pool"
 	"github.com/ricardo/mcp-ocr-server/internal/preprocessing"
+	"github.com/ricardo/mcp-ocr-server/internal/results"
 	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
 	"github.com/ricardo/mcp-ocr-server/pkg/logger"
 	"go.uber.org/zap"
@@ -23,24 +28,90 @@ import (
 
 // Handler OCR Tool Handler
 type Handler struct {
-	engine       ocr.Engine
-	preprocessor *preprocessing.Preprocessor
-	cache        *cache.Cache
-	workerPool   *pool.WorkerPool
-	config       *config.Config
+	engine           ocr.Engine
+	preprocessor     *preprocessing.Preprocessor
+	pdfRasterizer    *input.PDFRasterizer
+	sandbox          *input.PathSandbox
+	formats          map[input.Format]bool
+	urlFetcher       *input.URLFetcher
+	profiles         map[string]*profile
+	preprocessDigest string // 预处理配置摘要 (用于缓存键)
+	cache            cache.Backend
+	inflight         cache.Group      // 正在执行的识别 (按缓存键合并并发请求)
+	generation       cache.Generation // 缓存失效代数 (丢弃失效前开始的识别写入的结果)
+	workerPool       *pool.WorkerPool
+	jobManager       *jobs.Manager
+	resultStore      *results.Store
+	config           *config.Config
+}
+
+// recognizeParams 识别参数
+type recognizeParams struct {
+	Language    string               // 识别语言
+	Preprocess  bool                 // 是否预处理
+	AutoMode    bool                 // 是否自动分析图像质量
+	Format      ocr.OutputFormat     // 输出格式
+	Pipeline    []preprocessing.Step // 显式指定的预处理步骤 (为空时按配置自动选择)
+	Profile     string               // 使用的预处理配置名称
+	PageSegMode *int                 // 页面分割模式 (来自预处理配置，为空时使用引擎配置)
+	Whitelist   string               // 字符白名单 (来自预处理配置，为空时使用引擎配置)
+	Debug       bool                 // 是否返回预处理调试信息 (跳过缓存读取)
+	DebugImages bool                 // 调试时是否返回各步骤的中间图像
 }
 
 // NewHandler 创建 Tool Handler
 func NewHandler(cfg *config.Config) (*Handler, error) {
-	// 创建 OCR 引擎
+	// 创建路径沙箱
+	sandbox, err := input.NewPathSandbox(cfg.Security.AllowedRoots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path sandbox: %w", err)
+	}
+	if sandbox.Enabled() {
+		logger.Info("Path sandbox enabled", zap.Strings("allowed_roots", sandbox.Roots()))
+	}
+
+	// 解析允许的输入格式
+	allowedFormats, err := parseAllowedFormats(cfg.OCR.AllowedFormats)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed_formats: %w", err)
+	}
+
+	// 解析预处理配置
+	profiles, err := parseProfiles(cfg.Preprocessing.Profiles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid preprocessing profiles: %w", err)
+	}
+
+	// 创建远程图像下载器 (未启用时为 nil)
+	var urlFetcher *input.URLFetcher
+	if fetchCfg := cfg.Security.URLFetch; fetchCfg.Enabled {
+		urlFetcher, err = input.NewURLFetcher(input.URLFetcherOptions{
+			Timeout:      time.Duration(fetchCfg.Timeout) * time.Second,
+			MaxSize:      cfg.OCR.MaxImageSize,
+			MaxRedirects: fetchCfg.MaxRedirects,
+			AllowedHosts: fetchCfg.AllowedHosts,
+			DeniedHosts:  fetchCfg.DeniedHosts,
+			AllowPrivate: fetchCfg.AllowPrivate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create url fetcher: %w", err)
+		}
+	}
+
+	// 创建 OCR 引擎 (限制超时后仍在后台运行的识别数，默认等于 Worker 数)
+	maxAbandoned := cfg.OCR.MaxAbandoned
+	if maxAbandoned == 0 {
+		maxAbandoned = cfg.Performance.WorkerPoolSize
+	}
 	engine := ocr.NewTesseractEngine()
 	engineConfig := ocr.EngineConfig{
-		Language:    cfg.OCR.Language,
-		DataPath:    cfg.OCR.DataPath,
-		PageSegMode: cfg.OCR.PageSegMode,
-		EngineMode:  cfg.OCR.EngineMode,
-		Whitelist:   cfg.OCR.Whitelist,
-		Timeout:     time.Duration(cfg.OCR.Timeout) * time.Second,
+		Language:     cfg.OCR.Language,
+		DataPath:     cfg.OCR.DataPath,
+		PageSegMode:  cfg.OCR.PageSegMode,
+		EngineMode:   cfg.OCR.EngineMode,
+		Whitelist:    cfg.OCR.Whitelist,
+		Timeout:      time.Duration(cfg.OCR.Timeout) * time.Second,
+		MaxAbandoned: maxAbandoned,
 	}
 
 	if err := engine.Init(engineConfig); err != nil {
@@ -63,16 +134,29 @@ func NewHandler(cfg *config.Config) (*Handler, error) {
 		Resize:            cfg.Preprocessing.Resize,
 		ResizeWidth:       cfg.Preprocessing.ResizeWidth,
 		ResizeHeight:      cfg.Preprocessing.ResizeHeight,
+		MaxPixels:         cfg.OCR.MaxPixels,
 	}
 	preprocessorConfig.QualityThresholds.Sharpness = cfg.Preprocessing.QualityThresholds.Sharpness
 	preprocessorConfig.QualityThresholds.Contrast = cfg.Preprocessing.QualityThresholds.Contrast
 	preprocessorConfig.QualityThresholds.Brightness = cfg.Preprocessing.QualityThresholds.Brightness
+	preprocessorConfig.QualityThresholds.MinDPI = cfg.Preprocessing.QualityThresholds.MinDPI
 
 	preprocessor := preprocessing.NewPreprocessor(preprocessorConfig)
 
+	// 创建 PDF 栅格化器
+	pdfRasterizer := input.NewPDFRasterizer(input.PDFRasterizerOptions{
+		Renderer:  cfg.OCR.PDF.Renderer,
+		Info:      cfg.OCR.PDF.Info,
+		DPI:       cfg.OCR.PDF.DPI,
+		MaxPages:  cfg.OCR.PDF.MaxPages,
+		MaxPixels: cfg.OCR.MaxPixels,
+	})
+
 	// 创建缓存
-	cacheTTL := time.Duration(cfg.Performance.CacheTTL) * time.Second
-	resultCache := cache.NewCache(cfg.Performance.CacheSize, cacheTTL, cfg.Performance.CacheEnabled)
+	resultCache, err := newResultCache(cfg.Performance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create result cache: %w", err)
+	}
 
 	// 创建 Worker Pool
 	workerPool := pool.NewWorkerPool(cfg.Performance.WorkerPoolSize, cfg.Performance.QueueSize)
@@ -80,12 +164,35 @@ func NewHandler(cfg *config.Config) (*Handler, error) {
 		return nil, fmt.Errorf("failed to start worker pool: %w", err)
 	}
 
+	// 创建异步任务管理器 (限制任务项占用的 Worker 池名额，为交互式请求保留队列)
+	jobTTL := time.Duration(cfg.Performance.JobTTL) * time.Second
+	jobConcurrency := cfg.Performance.JobConcurrency
+	if jobConcurrency == 0 {
+		jobConcurrency = cfg.Performance.WorkerPoolSize
+	}
+	jobManager := jobs.NewManager(workerPool, jobTTL, cfg.Performance.MaxJobs, jobConcurrency)
+
+	// 创建识别结果存储 (供 MCP 资源读取)
+	var resultStore *results.Store
+	if cfg.Performance.ResultStoreSize > 0 {
+		resultTTL := time.Duration(cfg.Performance.ResultTTL) * time.Second
+		resultStore = results.NewStore(cfg.Performance.ResultStoreSize, cfg.Performance.ResultMaxBytes, resultTTL)
+	}
+
 	return &Handler{
-		engine:       engine,
-		preprocessor: preprocessor,
-		cache:        resultCache,
-		workerPool:   workerPool,
-		config:       cfg,
+		engine:           engine,
+		preprocessor:     preprocessor,
+		pdfRasterizer:    pdfRasterizer,
+		sandbox:          sandbox,
+		formats:          allowedFormats,
+		urlFetcher:       urlFetcher,
+		profiles:         profiles,
+		preprocessDigest: cache.Digest(preprocessorConfig),
+		cache:            resultCache,
+		workerPool:       workerPool,
+		jobManager:       jobManager,
+		resultStore:      resultStore,
+		config:           cfg,
 	}, nil
 }
 
@@ -93,15 +200,44 @@ func NewHandler(cfg *config.Config) (*Handler, error) {
 func (h *Handler) Handle(ctx context.Context, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	logger.Info("Tool called", zap.String("tool", toolName))
 
+	if IsAdminTool(toolName) && !h.config.Security.AdminTools {
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrUnauthorized, "admin tools are disabled (security.admin_tools)").
+			WithDetails("tool", toolName)), nil
+	}
+
 	switch toolName {
 	case "ocr_recognize_text":
 		return h.handleRecognizeText(ctx, arguments)
 	case "ocr_recognize_text_base64":
 		return h.handleRecognizeTextBase64(ctx, arguments)
+	case "ocr_recognize_url":
+		return h.handleRecognizeURL(ctx, arguments)
 	case "ocr_batch_recognize":
 		return h.handleBatchRecognize(ctx, arguments)
+	case "ocr_recognize_with_layout":
+		return h.handleRecognizeWithLayout(ctx, arguments)
+	case "ocr_create_searchable_pdf":
+		return h.handleCreateSearchablePDF(ctx, arguments)
+	case "ocr_submit_job":
+		return h.handleSubmitJob(ctx, arguments)
+	case "ocr_job_status":
+		return h.handleJobStatus(ctx, arguments)
+	case "ocr_job_results":
+		return h.handleJobResults(ctx, arguments)
+	case "ocr_cancel_job":
+		return h.handleCancelJob(ctx, arguments)
+	case "ocr_list_profiles":
+		return h.handleListProfiles(ctx, arguments)
+	case "ocr_analyze_image_quality":
+		return h.handleAnalyzeImageQuality(ctx, arguments)
 	case "ocr_get_supported_languages":
 		return h.handleGetSupportedLanguages(ctx, arguments)
+	case "ocr_cache_stats":
+		return h.handleCacheStats(ctx, arguments)
+	case "ocr_cache_invalidate":
+		return h.handleCacheInvalidate(ctx, arguments)
+	case "ocr_cache_clear":
+		return h.handleCacheClear(ctx, arguments)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", toolName)
 	}
@@ -115,9 +251,10 @@ func (h *Handler) handleRecognizeText(ctx context.Context, args map[string]inter
 		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_path is required")
 	}
 
-	language := h.getStringArg(args, "language", h.config.OCR.Language)
-	preprocess := h.getBoolArg(args, "preprocess", true)
-	autoMode := h.getBoolArg(args, "auto_mode", true)
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
 
 	// 读取图像文件
 	imageData, err := h.readImageFile(imagePath)
@@ -125,13 +262,16 @@ func (h *Handler) handleRecognizeText(ctx context.Context, args map[string]inter
 		return h.errorResult(err), nil
 	}
 
-	// 执行 OCR
-	result, err := h.recognizeImage(ctx, imageData, language, preprocess, autoMode)
+	// 在 Worker 池中执行 OCR (PDF 等多页文档按页识别)
+	ctx, debug := withDebug(ctx, params)
+	result, err := h.runTask(ctx, imagePath, func(ctx context.Context) (interface{}, error) {
+		return h.recognizeInput(ctx, imageData, params)
+	})
 	if err != nil {
 		return h.errorResult(err), nil
 	}
 
-	return h.successResult(result), nil
+	return debug.appendTo(h.storeResult(ctx, "ocr_recognize_text", result, imageData, params)), nil
 }
 
 // handleRecognizeTextBase64 处理 Base64 图像识别
@@ -142,9 +282,10 @@ func (h *Handler) handleRecognizeTextBase64(ctx context.Context, args map[string
 		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_base64 is required")
 	}
 
-	language := h.getStringArg(args, "language", h.config.OCR.Language)
-	preprocess := h.getBoolArg(args, "preprocess", true)
-	autoMode := h.getBoolArg(args, "auto_mode", true)
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
 
 	// 解码 Base64
 	imageData, err := base64.StdEncoding.DecodeString(imageBase64)
@@ -152,84 +293,106 @@ func (h *Handler) handleRecognizeTextBase64(ctx context.Context, args map[string
 		return h.errorResult(ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, "invalid base64 data")), nil
 	}
 
-	// 执行 OCR
-	result, err := h.recognizeImage(ctx, imageData, language, preprocess, autoMode)
+	// 在 Worker 池中执行 OCR
+	ctx, debug := withDebug(ctx, params)
+	result, err := h.runTask(ctx, "base64", func(ctx context.Context) (interface{}, error) {
+		return h.recognizeInput(ctx, imageData, params)
+	})
 	if err != nil {
 		return h.errorResult(err), nil
 	}
 
-	return h.successResult(result), nil
+	return debug.appendTo(h.storeResult(ctx, "ocr_recognize_text_base64", result, imageData, params)), nil
 }
 
-// handleBatchRecognize 处理批量识别
-func (h *Handler) handleBatchRecognize(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+// handleRecognizeURL 下载远程图像并识别
+func (h *Handler) handleRecognizeURL(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
 	// 解析参数
-	imagePathsInterface, ok := args["image_paths"].([]interface{})
-	if !ok {
-		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_paths is required")
+	imageURL, ok := args["image_url"].(string)
+	if !ok || imageURL == "" {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_url is required")
 	}
 
-	imagePaths := make([]string, 0, len(imagePathsInterface))
-	for _, p := range imagePathsInterface {
-		if path, ok := p.(string); ok {
-			imagePaths = append(imagePaths, path)
-		}
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
 	}
 
-	if len(imagePaths) == 0 {
-		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "no valid image paths provided")
+	// 下载图像 (在 Worker 池外进行，避免网络等待占用 OCR Worker)
+	imageData, err := h.fetchImage(ctx, imageURL)
+	if err != nil {
+		return h.errorResult(err), nil
 	}
 
-	language := h.getStringArg(args, "language", h.config.OCR.Language)
-	preprocess := h.getBoolArg(args, "preprocess", true)
-	autoMode := h.getBoolArg(args, "auto_mode", true)
+	// 在 Worker 池中执行 OCR
+	ctx, debug := withDebug(ctx, params)
+	result, err := h.runTask(ctx, imageURL, func(ctx context.Context) (interface{}, error) {
+		return h.recognizeInput(ctx, imageData, params)
+	})
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	return debug.appendTo(h.storeResult(ctx, "ocr_recognize_url", result, imageData, params)), nil
+}
 
-	// 并行处理
+// handleBatchRecognize 处理批量识别
+func (h *Handler) handleBatchRecognize(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	// 解析参数
+	imagePaths, err := h.getImagePathsArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	// 提交到 Worker 池 (并发数受 worker_pool_size 限制，队列满时等待空位)
 	results := make([]map[string]interface{}, len(imagePaths))
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+	futures := make([]*pool.Future, len(imagePaths))
+	progress := newProgressTracker(ctx, len(imagePaths))
 
 	for i, path := range imagePaths {
-		wg.Add(1)
-		go func(index int, imagePath string) {
-			defer wg.Done()
-
+		imagePath := path
+		future, err := h.submitTask(ctx, imagePath, func(ctx context.Context) (interface{}, error) {
 			imageData, err := h.readImageFile(imagePath)
 			if err != nil {
-				mu.Lock()
-				results[index] = map[string]interface{}{
-					"path":  imagePath,
-					"error": err.Error(),
-				}
-				mu.Unlock()
-				return
+				return nil, err
 			}
-
-			result, err := h.recognizeImage(ctx, imageData, language, preprocess, autoMode)
-			if err != nil {
-				mu.Lock()
-				results[index] = map[string]interface{}{
-					"path":  imagePath,
-					"error": err.Error(),
-				}
-				mu.Unlock()
-				return
+			// 按图像报告进度，不报告多页文档内部的页进度
+			return h.recognizeInput(withoutProgress(ctx), imageData, params)
+		})
+		if err != nil {
+			results[i] = map[string]interface{}{
+				"path":  imagePath,
+				"error": err.Error(),
 			}
+			progress.step(imagePath)
+			continue
+		}
+		futures[i] = future
+	}
+
+	// 按提交顺序收集结果 (在此报告进度，未执行的任务项同样计入)
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
 
-			mu.Lock()
-			resultMap := map[string]interface{}{
-				"path":       imagePath,
-				"text":       result.Text,
-				"confidence": result.Confidence,
-				"language":   result.Language,
-				"duration":   result.Duration.Seconds(),
+		result, err := h.waitTask(ctx, future)
+		progress.step(imagePaths[i])
+		if err != nil {
+			results[i] = map[string]interface{}{
+				"path":  imagePaths[i],
+				"error": err.Error(),
 			}
-			results[index] = resultMap
-			mu.Unlock()
-		}(i, path)
-	}
+			continue
+		}
 
-	wg.Wait()
+		results[i] = batchResultMap(imagePaths[i], result)
+	}
 
 	return h.successResult(map[string]interface{}{
 		"results": results,
@@ -237,6 +400,247 @@ func (h *Handler) handleBatchRecognize(ctx context.Context, args map[string]inte
 	}), nil
 }
 
+// handleRecognizeWithLayout 处理带版面信息的识别
+func (h *Handler) handleRecognizeWithLayout(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	// 解析参数
+	level, err := ocr.ParseLayoutLevel(h.getStringArg(args, "level", string(ocr.LayoutLevelWord)))
+	if err != nil {
+		return h.errorResult(ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, "invalid level")), nil
+	}
+
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	// 读取图像 (文件路径或 Base64)
+	imageData, err := h.readImageInput(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	info, err := h.inspectInput(imageData)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	// 在 Worker 池中执行 OCR
+	ctx, debug := withDebug(ctx, params)
+	value, err := h.runTask(ctx, "layout", func(ctx context.Context) (interface{}, error) {
+		return h.recognizeLayout(ctx, imageData, params, level)
+	})
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+	result := value.(*ocr.DetailedResult)
+
+	items := make([]map[string]interface{}, 0, len(result.BoundingBox))
+	for _, box := range result.BoundingBox {
+		item := map[string]interface{}{
+			"text":       box.Text,
+			"confidence": box.Conf,
+			"bbox": map[string]interface{}{
+				"x":      box.X,
+				"y":      box.Y,
+				"width":  box.Width,
+				"height": box.Height,
+			},
+		}
+		if level == ocr.LayoutLevelWord {
+			item["block_num"] = box.BlockNum
+			item["par_num"] = box.ParNum
+			item["line_num"] = box.LineNum
+			item["word_num"] = box.WordNum
+		}
+		items = append(items, item)
+	}
+
+	return debug.appendTo(h.storeResult(ctx, "ocr_recognize_with_layout", map[string]interface{}{
+		"text":         result.Text,
+		"confidence":   result.Confidence,
+		"language":     result.Language,
+		"level":        string(result.Level),
+		"items":        items,
+		"count":        len(items),
+		"duration":     result.Duration.Seconds(),
+		"input_format": string(info.Format),
+	}, imageData, params)), nil
+}
+
+// handleCreateSearchablePDF 生成带不可见文本层的可搜索 PDF
+func (h *Handler) handleCreateSearchablePDF(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	// 解析参数
+	imagePaths, err := h.getImagePathsArg(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	outputPath, ok := args["output_path"].(string)
+	if !ok || outputPath == "" {
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "output_path is required")), nil
+	}
+
+	// 输出路径同样受沙箱限制，在识别前检查
+	cleanOutput, err := h.sandbox.ResolveOutput(outputPath)
+	if err != nil {
+		return h.errorResult(h.pathError(err, outputPath)), nil
+	}
+
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	dpi := h.getFloatArg(args, "dpi", 300)
+	if dpi <= 0 {
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "dpi must be positive")), nil
+	}
+
+	// 各页提交到 Worker 池并行识别
+	futures := make([]*pool.Future, 0, len(imagePaths))
+	progress := newProgressTracker(ctx, len(imagePaths))
+	for _, path := range imagePaths {
+		imagePath := path
+		future, err := h.submitTask(ctx, imagePath, func(ctx context.Context) (interface{}, error) {
+			return h.buildPDFPages(ctx, imagePath, params, dpi)
+		})
+		if err != nil {
+			return h.errorResult(err), nil
+		}
+		futures = append(futures, future)
+	}
+
+	pages := make([]pdf.Page, 0, len(imagePaths))
+	pageResults := make([]map[string]interface{}, 0, len(imagePaths))
+	for i, future := range futures {
+		value, err := h.waitTask(ctx, future)
+		progress.step(imagePaths[i])
+		if err != nil {
+			if ocrErr, ok := err.(*ocrErrors.OCRError); ok {
+				err = ocrErr.Clone().WithDetails("page", i+1).WithDetails("path", imagePaths[i])
+			}
+			return h.errorResult(err), nil
+		}
+
+		// PDF 和多页 TIFF 的每一页分别生成一个页面
+		sourcePages := value.([]*pdfPage)
+		for j, page := range sourcePages {
+			pages = append(pages, page.page)
+			pageResult := map[string]interface{}{
+				"page":       len(pages),
+				"path":       imagePaths[i],
+				"text":       page.result.Text,
+				"confidence": page.result.Confidence,
+				"words":      len(page.page.Words),
+			}
+			if len(sourcePages) > 1 {
+				pageResult["source_page"] = j + 1
+			}
+			pageResults = append(pageResults, pageResult)
+		}
+	}
+
+	// 写入 PDF
+	var buf bytes.Buffer
+	if err := pdf.Write(&buf, pages); err != nil {
+		return h.errorResult(ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "failed to generate PDF")), nil
+	}
+
+	if err := os.WriteFile(cleanOutput, buf.Bytes(), 0644); err != nil {
+		return h.errorResult(ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "failed to write PDF file")), nil
+	}
+
+	logger.Info("Searchable PDF created",
+		zap.String("output_path", cleanOutput),
+		zap.Int("pages", len(pages)),
+		zap.Int("size", buf.Len()),
+	)
+
+	return h.successResult(map[string]interface{}{
+		"output_path": cleanOutput,
+		"pages":       pageResults,
+		"page_count":  len(pages),
+		"size":        buf.Len(),
+	}), nil
+}
+
+// pdfPage 可搜索 PDF 的单页及其识别结果
+type pdfPage struct {
+	page   pdf.Page
+	result *ocr.DetailedResult
+}
+
+// buildPDFPages 读取输入文件并为其每一页构建 PDF 页面 (PDF 和多页 TIFF 先拆分为单页图像)
+func (h *Handler) buildPDFPages(ctx context.Context, imagePath string, params recognizeParams, dpi float64) ([]*pdfPage, error) {
+	imageData, err := h.readImageFile(imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := h.inspectInput(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := h.splitPages(ctx, imageData, info.Format)
+	if err != nil {
+		return nil, err
+	}
+	if images == nil {
+		images = [][]byte{imageData}
+	}
+
+	pages := make([]*pdfPage, 0, len(images))
+	for i, image := range images {
+		page, err := h.buildPDFPage(ctx, image, params, dpi)
+		if err != nil {
+			if ocrErr, ok := err.(*ocrErrors.OCRError); ok && len(images) > 1 {
+				return nil, ocrErr.Clone().WithDetails("source_page", i+1)
+			}
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// buildPDFPage 识别单页图像并构建 PDF 页面 (原始图像 + 单词边界框)
+func (h *Handler) buildPDFPage(ctx context.Context, imageData []byte, params recognizeParams, dpi float64) (*pdfPage, error) {
+	result, err := h.recognizeLayout(ctx, imageData, params, ocr.LayoutLevelWord)
+	if err != nil {
+		return nil, err
+	}
+
+	// 页面使用原始图像，文本层坐标需要从预处理后的图像映射回原图
+	jpegData, err := preprocessing.EncodeJPEG(imageData, 90)
+	if err != nil {
+		return nil, err
+	}
+
+	scaleX, scaleY := 1.0, 1.0
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(jpegData)); err == nil && result.Width > 0 && result.Height > 0 {
+		scaleX = float64(cfg.Width) / float64(result.Width)
+		scaleY = float64(cfg.Height) / float64(result.Height)
+	}
+
+	words := make([]pdf.Word, 0, len(result.BoundingBox))
+	for _, box := range result.BoundingBox {
+		words = append(words, pdf.Word{
+			Text:   box.Text,
+			X:      int(float64(box.X) * scaleX),
+			Y:      int(float64(box.Y) * scaleY),
+			Width:  int(float64(box.Width) * scaleX),
+			Height: int(float64(box.Height) * scaleY),
+		})
+	}
+
+	return &pdfPage{
+		page:   pdf.Page{JPEG: jpegData, DPI: dpi, Words: words},
+		result: result,
+	}, nil
+}
+
 // handleGetSupportedLanguages 获取支持的语言
 func (h *Handler) handleGetSupportedLanguages(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
 	languages := h.engine.GetSupportedLanguages()
@@ -246,59 +650,215 @@ func (h *Handler) handleGetSupportedLanguages(ctx context.Context, args map[stri
 	}), nil
 }
 
+// recognizeFunc 对预处理后的图像执行 OCR，返回可缓存的识别结果
+type recognizeFunc func(ctx context.Context, data []byte, opts ocr.RecognizeOptions, info input.ImageInfo) (interface{}, error)
+
 // recognizeImage 识别图像
-func (h *Handler) recognizeImage(ctx context.Context, imageData []byte, language string, preprocess, autoMode bool) (*ocr.RecognizeResult, error) {
-	// 检查图像大小
-	if int64(len(imageData)) > h.config.OCR.MaxImageSize {
-		return nil, ocrErrors.New(ocrErrors.ErrImageTooLarge, fmt.Sprintf("image size exceeds limit: %d bytes", len(imageData)))
+func (h *Handler) recognizeImage(ctx context.Context, imageData []byte, params recognizeParams) (*ocr.RecognizeResult, error) {
+	value, err := h.recognize(ctx, imageData, params, "", func(ctx context.Context, data []byte, opts ocr.RecognizeOptions, info input.ImageInfo) (interface{}, error) {
+		result, err := h.engine.RecognizeText(ctx, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.InputFormat = string(info.Format)
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := value.(*ocr.RecognizeResult)
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrInternalError, fmt.Sprintf("unexpected result type %T", value))
 	}
+	return result, nil
+}
 
-	// 生成缓存键
-	cacheKey := cache.GenerateKey(imageData, language, fmt.Sprintf("%t", preprocess))
+// recognizeLayout 识别图像并返回版面信息
+func (h *Handler) recognizeLayout(ctx context.Context, imageData []byte, params recognizeParams, level ocr.LayoutLevel) (*ocr.DetailedResult, error) {
+	value, err := h.recognize(ctx, imageData, params, level, func(ctx context.Context, data []byte, opts ocr.RecognizeOptions, info input.ImageInfo) (interface{}, error) {
+		return h.engine.RecognizeWithDetails(ctx, data, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// 检查缓存
-	if cached, found := h.cache.Get(cacheKey); found {
-		if result, ok := cached.(*ocr.RecognizeResult); ok {
-			logger.Info("OCR result from cache", zap.String("language", language))
-			return result, nil
+	result, ok := value.(*ocr.DetailedResult)
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrInternalError, fmt.Sprintf("unexpected result type %T", value))
+	}
+	return result, nil
+}
+
+// recognize 检查图像并通过缓存和请求合并执行识别，level 为空表示纯文本识别
+func (h *Handler) recognize(ctx context.Context, imageData []byte, params recognizeParams, level ocr.LayoutLevel, fn recognizeFunc) (interface{}, error) {
+	// 检查图像大小、格式和像素数
+	info, err := h.inspectImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成缓存键，记录缓存失效代数
+	cacheKey := h.fingerprint(params, level).Key(imageData)
+	gen := h.generation.Current(cacheKey)
+
+	// 检查缓存 (调试时需要实际执行预处理，不读取缓存)
+	if !params.Debug {
+		if cached, found := h.cache.Get(cacheKey); found {
+			logger.Info("OCR result from cache",
+				zap.String("language", params.Language),
+				zap.String("level", string(level)),
+			)
+			return cached, nil
 		}
 	}
 
+	// 调试时需要记录本次调用的预处理过程，不与其他请求合并
+	if params.Debug {
+		return h.executeRecognize(ctx, imageData, info, params, level, fn, cacheKey, gen)
+	}
+
+	return h.coalesce(ctx, cacheKey, gen, func(ctx context.Context) (interface{}, error) {
+		return h.executeRecognize(ctx, imageData, info, params, level, fn, cacheKey, gen)
+	})
+}
+
+// executeRecognize 预处理并识别图像，缓存结果 (识别期间缓存被失效时不写入)
+func (h *Handler) executeRecognize(ctx context.Context, imageData []byte, info input.ImageInfo, params recognizeParams, level ocr.LayoutLevel, fn recognizeFunc, cacheKey string, gen uint64) (interface{}, error) {
+	// 预处理和 OCR 共用配置的超时时间
+	ctx, cancel := h.withOCRTimeout(ctx)
+	defer cancel()
+
 	// 预处理
-	processedData := imageData
-	if preprocess {
-		var err error
-		processedData, err = h.preprocessor.Process(imageData)
-		if err != nil {
-			logger.Warn("Preprocessing failed, using original image", zap.Error(err))
-			processedData = imageData
-		}
+	processedData, err := h.preprocess(ctx, imageData, params)
+	if err != nil {
+		return nil, err
 	}
 
 	// 执行 OCR
 	opts := ocr.RecognizeOptions{
-		Language:   language,
-		Preprocess: preprocess,
+		Language:    params.Language,
+		PageSegMode: params.PageSegMode,
+		Whitelist:   params.Whitelist,
+		Preprocess:  params.Preprocess,
+		Level:       level,
 		Metadata: map[string]string{
-			"auto_mode": fmt.Sprintf("%t", autoMode),
+			"auto_mode": fmt.Sprintf("%t", params.AutoMode),
 		},
 	}
 
-	result, err := h.engine.RecognizeText(ctx, processedData, opts)
+	result, err := fn(ctx, processedData, opts, info)
 	if err != nil {
 		return nil, err
 	}
 
 	// 缓存结果
-	h.cache.Set(cacheKey, result)
+	h.cacheResult(cacheKey, gen, result)
 
 	return result, nil
 }
 
-// readImageFile 读取图像文件
+// parseRecognizeParams 解析通用识别参数
+func (h *Handler) parseRecognizeParams(args map[string]interface{}) (recognizeParams, error) {
+	format, err := ocr.ParseOutputFormat(h.getStringArg(args, "output_format", string(ocr.FormatText)))
+	if err != nil {
+		return recognizeParams{}, ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, "invalid output_format")
+	}
+
+	pipeline, err := h.getPipelineArg(args)
+	if err != nil {
+		return recognizeParams{}, err
+	}
+
+	preprocess := h.getBoolArg(args, "preprocess", true)
+	if len(pipeline) > 0 && !preprocess {
+		return recognizeParams{}, ocrErrors.New(ocrErrors.ErrInvalidInput, "pipeline cannot be used with preprocess: false")
+	}
+
+	debug := h.getBoolArg(args, "debug", false)
+
+	params := recognizeParams{
+		Language:    h.config.OCR.Language,
+		Preprocess:  preprocess,
+		AutoMode:    h.getBoolArg(args, "auto_mode", h.config.Preprocessing.AutoMode),
+		Format:      format,
+		Pipeline:    pipeline,
+		Debug:       debug,
+		DebugImages: debug && h.getBoolArg(args, "debug_images", false),
+	}
+
+	// 预处理配置提供默认值，显式指定的 language 和 pipeline 参数优先
+	profile, err := h.getProfileArg(args)
+	if err != nil {
+		return recognizeParams{}, err
+	}
+	if profile != nil {
+		params.Profile = profile.name
+		params.PageSegMode = profile.config.PageSegMode
+		params.Whitelist = profile.config.Whitelist
+		if profile.config.Language != "" {
+			params.Language = profile.config.Language
+		}
+		if len(params.Pipeline) == 0 && preprocess {
+			params.Pipeline = profile.steps
+		}
+	}
+	params.Language = h.getStringArg(args, "language", params.Language)
+
+	return params, nil
+}
+
+// fingerprint 识别指纹 (影响识别结果的全部选项)，用于缓存键；level 为空表示纯文本识别
+func (h *Handler) fingerprint(params recognizeParams, level ocr.LayoutLevel) cache.Fingerprint {
+	psm := h.config.OCR.PageSegMode
+	if params.PageSegMode != nil {
+		psm = *params.PageSegMode
+	}
+
+	whitelist := h.config.OCR.Whitelist
+	if params.Whitelist != "" {
+		whitelist = params.Whitelist
+	}
+
+	return cache.Fingerprint{
+		Level:            string(level),
+		Language:         params.Language,
+		PageSegMode:      psm,
+		EngineMode:       h.config.OCR.EngineMode,
+		Whitelist:        whitelist,
+		Preprocess:       params.Preprocess,
+		AutoMode:         params.AutoMode,
+		Pipeline:         preprocessing.PipelineKey(params.Pipeline),
+		PreprocessConfig: h.preprocessDigest,
+		EngineVersion:    h.engine.Version(),
+		DataChecksum:     h.engine.DataChecksum(params.Language),
+	}
+}
+
+// readImageInput 从 image_path 或 image_base64 参数读取图像
+func (h *Handler) readImageInput(args map[string]interface{}) ([]byte, error) {
+	if imagePath, ok := args["image_path"].(string); ok && imagePath != "" {
+		return h.readImageFile(imagePath)
+	}
+
+	if imageBase64, ok := args["image_base64"].(string); ok && imageBase64 != "" {
+		imageData, err := base64.StdEncoding.DecodeString(imageBase64)
+		if err != nil {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, "invalid base64 data")
+		}
+		return imageData, nil
+	}
+
+	return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_path or image_base64 is required")
+}
+
+// readImageFile 读取图像文件 (路径必须在允许的根目录内)
 func (h *Handler) readImageFile(path string) ([]byte, error) {
-	// 清理路径
-	cleanPath := filepath.Clean(path)
+	// 清理路径并解析符号链接
+	cleanPath, err := h.sandbox.Resolve(path)
+	if err != nil {
+		return nil, h.pathError(err, path)
+	}
 
 	// 检查文件是否存在
 	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
@@ -314,6 +874,57 @@ func (h *Handler) readImageFile(path string) ([]byte, error) {
 	return data, nil
 }
 
+// fetchImage 下载远程图像
+func (h *Handler) fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	if h.urlFetcher == nil {
+		return nil, ocrErrors.New(ocrErrors.ErrURLNotAllowed, "url input is disabled")
+	}
+
+	startTime := time.Now()
+	data, contentType, err := h.urlFetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, input.ErrURLNotAllowed):
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrURLNotAllowed, "url is not allowed").
+				WithDetails("url", imageURL)
+		case errors.Is(err, input.ErrContentTooLarge):
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrImageTooLarge, "remote image exceeds size limit").
+				WithDetails("max_image_size", h.config.OCR.MaxImageSize)
+		case errors.Is(err, input.ErrContentType):
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrUnsupportedFormat, "remote content is not an image").
+				WithDetails("content_type", contentType)
+		case ctx.Err() != nil || os.IsTimeout(err):
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrTimeout, "image download timed out or was cancelled").
+				WithDetails("timeout", h.config.Security.URLFetch.Timeout)
+		default:
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrFetchFailed, "failed to download image").
+				WithDetails("url", imageURL)
+		}
+	}
+
+	logger.Info("Remote image downloaded",
+		zap.String("url", imageURL),
+		zap.String("content_type", contentType),
+		zap.Int("size", len(data)),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+
+	return data, nil
+}
+
+// pathError 将路径解析错误转换为 OCR 错误
+func (h *Handler) pathError(err error, path string) error {
+	switch {
+	case errors.Is(err, input.ErrPathNotAllowed):
+		return ocrErrors.New(ocrErrors.ErrPathNotAllowed, fmt.Sprintf("path is outside allowed roots: %s", path)).
+			WithDetails("path", path)
+	case os.IsNotExist(err):
+		return ocrErrors.New(ocrErrors.ErrFileNotFound, fmt.Sprintf("file not found: %s", path))
+	default:
+		return ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, fmt.Sprintf("invalid path: %s", path))
+	}
+}
+
 // successResult 创建成功结果
 func (h *Handler) successResult(data interface{}) *mcp.CallToolResult {
 	jsonData, _ := json.Marshal(data)
@@ -330,6 +941,11 @@ func (h *Handler) successResult(data interface{}) *mcp.CallToolResult {
 
 // errorResult 创建错误结果
 func (h *Handler) errorResult(err error) *mcp.CallToolResult {
+	return ErrorResult(err)
+}
+
+// ErrorResult 将错误转换为工具错误结果 (OCR 错误包含 code 和 details)
+func ErrorResult(err error) *mcp.CallToolResult {
 	errData := map[string]interface{}{
 		"error": err.Error(),
 	}
@@ -352,6 +968,56 @@ func (h *Handler) errorResult(err error) *mcp.CallToolResult {
 	}
 }
 
+// getImagePathsArg 获取 image_paths 数组参数
+func (h *Handler) getImagePathsArg(args map[string]interface{}) ([]string, error) {
+	imagePathsInterface, ok := args["image_paths"].([]interface{})
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "image_paths is required")
+	}
+
+	imagePaths := make([]string, 0, len(imagePathsInterface))
+	for _, p := range imagePathsInterface {
+		if path, ok := p.(string); ok {
+			imagePaths = append(imagePaths, path)
+		}
+	}
+
+	if len(imagePaths) == 0 {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "no valid image paths provided")
+	}
+
+	return imagePaths, nil
+}
+
+// getPipelineArg 解析 pipeline 参数: 按顺序排列的步骤列表，如 [{"step": "denoise", "strength": 7}]
+func (h *Handler) getPipelineArg(args map[string]interface{}) ([]preprocessing.Step, error) {
+	raw, ok := args["pipeline"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "pipeline must be an array of steps")
+	}
+
+	steps := make([]preprocessing.Step, 0, len(items))
+	for i, item := range items {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, fmt.Sprintf("pipeline[%d] must be an object", i))
+		}
+
+		step, err := preprocessing.ParseStep(spec)
+		if err != nil {
+			return nil, ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, fmt.Sprintf("invalid pipeline[%d]", i))
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
 // getStringArg 获取字符串参数
 func (h *Handler) getStringArg(args map[string]interface{}, key, defaultValue string) string {
 	if val, ok := args[key].(string); ok {
@@ -368,9 +1034,18 @@ func (h *Handler) getBoolArg(args map[string]interface{}, key string, defaultVal
 	return defaultValue
 }
 
+// getFloatArg 获取数值参数 (JSON 数字统一解码为 float64)
+func (h *Handler) getFloatArg(args map[string]interface{}, key string, defaultValue float64) float64 {
+	if val, ok := args[key].(float64); ok {
+		return val
+	}
+	return defaultValue
+}
+
 // Close 关闭 Handler
 func (h *Handler) Close() error {
+	h.jobManager.Close()
 	h.workerPool.Stop()
-	h.cache.Clear()
+	h.cache.Close()
 	return h.engine.Close()
 }
\ No newline at end of file
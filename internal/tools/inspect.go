@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ricardo/mcp-ocr-server/internal/input"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+// parseAllowedFormats 解析允许的输入格式，列表为空时返回 nil (允许全部可识别格式)
+func parseAllowedFormats(names []string) (map[input.Format]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	formats := make(map[input.Format]bool, len(names))
+	for _, name := range names {
+		format, err := input.ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		formats[format] = true
+	}
+	return formats, nil
+}
+
+// inspectImage 检查图像大小、格式和声明的像素数，在解码前拒绝无法识别的数据和解压炸弹
+func (h *Handler) inspectImage(data []byte) (input.ImageInfo, error) {
+	if int64(len(data)) > h.config.OCR.MaxImageSize {
+		return input.ImageInfo{}, ocrErrors.New(ocrErrors.ErrImageTooLarge, fmt.Sprintf("image size exceeds limit: %d bytes", len(data)))
+	}
+
+	info, err := input.Inspect(data)
+	switch {
+	case errors.Is(err, input.ErrUnknownFormat):
+		return info, ocrErrors.New(ocrErrors.ErrUnsupportedFormat, "unrecognized image format").
+			WithDetails("supported_formats", input.Formats)
+	case err != nil:
+		return info, ocrErrors.Wrap(err, ocrErrors.ErrInvalidInput, fmt.Sprintf("invalid %s image", info.Format)).
+			WithDetails("format", info.Format)
+	}
+
+	if maxPixels := h.config.OCR.MaxPixels; maxPixels > 0 && info.ExceedsPixels(maxPixels) {
+		return info, ocrErrors.New(ocrErrors.ErrImageTooLarge,
+			fmt.Sprintf("image dimensions %dx%d exceed pixel limit: %d", info.Width, info.Height, maxPixels)).
+			WithDetails("format", info.Format).
+			WithDetails("width", info.Width).
+			WithDetails("height", info.Height).
+			WithDetails("max_pixels", maxPixels)
+	}
+
+	return info, nil
+}
+
+// inspectInput 检查客户端提供的输入，除 inspectImage 的检查外还要求格式在允许列表中
+// (PDF 栅格化等内部生成的页面只经过 inspectImage 检查)
+func (h *Handler) inspectInput(data []byte) (input.ImageInfo, error) {
+	info, err := h.inspectImage(data)
+	if err != nil {
+		return info, err
+	}
+
+	if h.formats != nil && !h.formats[info.Format] {
+		return info, ocrErrors.New(ocrErrors.ErrUnsupportedFormat, fmt.Sprintf("format not allowed: %s", info.Format)).
+			WithDetails("format", info.Format).
+			WithDetails("allowed_formats", h.config.OCR.AllowedFormats)
+	}
+
+	return info, nil
+}
\ No newline at end of file
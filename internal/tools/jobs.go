@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/jobs"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+// handleSubmitJob 提交异步批量识别任务，立即返回任务 ID
+func (h *Handler) handleSubmitJob(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	// 解析参数
+	imagePaths, err := h.getImagePathsArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := h.parseRecognizeParams(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	// 任务在后台运行，不受本次调用 ctx 的影响；取消通过 ocr_cancel_job 传递
+	// 任务只对提交它的调用方可见
+	job, err := h.jobManager.Submit(ownerFromContext(ctx), imagePaths, func(ctx context.Context, imagePath string) (interface{}, error) {
+		imageData, err := h.readImageFile(imagePath)
+		if err != nil {
+			return nil, err
+		}
+		return h.recognizeInput(ctx, imageData, params)
+	})
+	if err != nil {
+		return h.errorResult(h.jobError(err)), nil
+	}
+
+	return h.successResult(jobStatusMap(job)), nil
+}
+
+// handleJobStatus 查询异步任务状态和进度
+func (h *Handler) handleJobStatus(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	job, err := h.getJob(ctx, args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	return h.successResult(jobStatusMap(job)), nil
+}
+
+// handleJobResults 获取异步任务结果 (任务未结束时返回已完成部分)
+func (h *Handler) handleJobResults(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	job, err := h.getJob(ctx, args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	offset := int(h.getFloatArg(args, "offset", 0))
+	limit := int(h.getFloatArg(args, "limit", 0))
+	if offset < 0 || limit < 0 {
+		return h.errorResult(ocrErrors.New(ocrErrors.ErrInvalidInput, "offset and limit must not be negative")), nil
+	}
+
+	items := job.Items(offset, limit)
+	results := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		results = append(results, jobItemMap(item))
+	}
+
+	response := jobStatusMap(job)
+	response["results"] = results
+	response["offset"] = offset
+	response["count"] = len(results)
+
+	return h.successResult(response), nil
+}
+
+// handleCancelJob 取消异步任务
+func (h *Handler) handleCancelJob(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	job, err := h.getJob(ctx, args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	job, err = h.jobManager.Cancel(job.ID)
+	if err != nil {
+		return h.errorResult(h.jobError(err)), nil
+	}
+
+	return h.successResult(jobStatusMap(job)), nil
+}
+
+// getJob 根据 job_id 参数获取任务，其他调用方提交的任务按不存在处理
+func (h *Handler) getJob(ctx context.Context, args map[string]interface{}) (*jobs.Job, error) {
+	jobID := h.getStringArg(args, "job_id", "")
+	if jobID == "" {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, "job_id is required")
+	}
+
+	job, err := h.jobManager.Get(jobID)
+	if err != nil {
+		return nil, h.jobError(err)
+	}
+
+	if c := callerFromContext(ctx); c != nil && job.Owner != c.name {
+		return nil, h.jobError(jobs.ErrJobNotFound)
+	}
+
+	return job, nil
+}
+
+// jobError 将任务管理器错误转换为 OCR 错误
+func (h *Handler) jobError(err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return ocrErrors.Wrap(err, ocrErrors.ErrJobNotFound, "job not found or expired")
+	case errors.Is(err, jobs.ErrTooManyJobs):
+		return ocrErrors.Wrap(err, ocrErrors.ErrQueueFull, "too many jobs, retry later").
+			WithDetails("max_jobs", h.config.Performance.MaxJobs)
+	default:
+		return ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "job operation failed")
+	}
+}
+
+// jobStatusMap 将任务状态转换为响应
+func jobStatusMap(job *jobs.Job) map[string]interface{} {
+	progress := job.Progress()
+
+	status := map[string]interface{}{
+		"job_id":     job.ID,
+		"status":     string(job.Status()),
+		"created_at": job.CreatedAt.Format(time.RFC3339),
+		"progress": map[string]interface{}{
+			"total":     progress.Total,
+			"pending":   progress.Pending,
+			"running":   progress.Running,
+			"completed": progress.Completed,
+			"failed":    progress.Failed,
+			"cancelled": progress.Cancelled,
+			"percent":   100 * float64(progress.Done()) / float64(progress.Total),
+		},
+	}
+
+	if finishedAt, ok := job.FinishedAt(); ok {
+		status["finished_at"] = finishedAt.Format(time.RFC3339)
+		status["duration"] = finishedAt.Sub(job.CreatedAt).Seconds()
+	}
+
+	return status
+}
+
+// jobItemMap 将任务项转换为批量结果条目
+func jobItemMap(item jobs.Item) map[string]interface{} {
+	var result map[string]interface{}
+	if item.Status == jobs.StatusCompleted {
+		result = batchResultMap(item.Input, item.Result)
+	} else {
+		result = map[string]interface{}{"path": item.Input}
+		if item.Err != nil {
+			result["error"] = item.Err.Error()
+		}
+	}
+
+	result["index"] = item.Index
+	result["status"] = string(item.Status)
+	return result
+}
\ No newline at end of file
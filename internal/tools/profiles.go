@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/config"
+	"github.com/ricardo/mcp-ocr-server/internal/preprocessing"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+// profile 解析后的命名预处理配置
+type profile struct {
+	name   string
+	config config.PreprocessingProfile
+	steps  []preprocessing.Step
+}
+
+// parseProfiles 解析并验证配置中的预处理配置 (步骤名称和参数)
+func parseProfiles(profiles map[string]config.PreprocessingProfile) (map[string]*profile, error) {
+	parsed := make(map[string]*profile, len(profiles))
+
+	for name, cfg := range profiles {
+		steps := make([]preprocessing.Step, 0, len(cfg.Pipeline))
+		for i, spec := range cfg.Pipeline {
+			step, err := preprocessing.ParseStep(spec)
+			if err != nil {
+				return nil, fmt.Errorf("profile %q pipeline[%d]: %w", name, i, err)
+			}
+			steps = append(steps, step)
+		}
+
+		parsed[name] = &profile{
+			name:   name,
+			config: cfg,
+			steps:  steps,
+		}
+	}
+
+	return parsed, nil
+}
+
+// profileNames 预处理配置名称 (按名称排序)
+func (h *Handler) profileNames() []string {
+	names := make([]string, 0, len(h.profiles))
+	for name := range h.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getProfileArg 获取 profile 参数指定的预处理配置，未指定时返回 nil
+func (h *Handler) getProfileArg(args map[string]interface{}) (*profile, error) {
+	name := h.getStringArg(args, "profile", "")
+	if name == "" {
+		return nil, nil
+	}
+
+	p, ok := h.profiles[name]
+	if !ok {
+		return nil, ocrErrors.New(ocrErrors.ErrInvalidInput, fmt.Sprintf("unknown profile %q", name)).
+			WithDetails("available", strings.Join(h.profileNames(), ", "))
+	}
+
+	return p, nil
+}
+
+// handleListProfiles 列出配置中的预处理配置和可用的预处理步骤
+func (h *Handler) handleListProfiles(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	profiles := make([]map[string]interface{}, 0, len(h.profiles))
+
+	for _, name := range h.profileNames() {
+		p := h.profiles[name]
+
+		pipeline := make([]map[string]interface{}, 0, len(p.steps))
+		for _, step := range p.steps {
+			entry := map[string]interface{}{"step": step.Name}
+			for key, value := range step.Params {
+				entry[key] = value
+			}
+			pipeline = append(pipeline, entry)
+		}
+
+		item := map[string]interface{}{
+			"name":        name,
+			"description": p.config.Description,
+			"pipeline":    pipeline,
+		}
+		if p.config.Language != "" {
+			item["language"] = p.config.Language
+		}
+		if p.config.PageSegMode != nil {
+			item["page_seg_mode"] = *p.config.PageSegMode
+		}
+		if p.config.Whitelist != "" {
+			item["whitelist"] = p.config.Whitelist
+		}
+
+		profiles = append(profiles, item)
+	}
+
+	return h.successResult(map[string]interface{}{
+		"profiles": profiles,
+		"count":    len(profiles),
+		"steps":    preprocessing.StepNames(),
+	}), nil
+}
\ No newline at end of file
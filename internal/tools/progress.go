@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProgressFunc 进度回调 (done 为已完成数量，total 为总数，message 为进度描述)
+type ProgressFunc func(done, total int, message string)
+
+// progressKey context 中进度回调的键
+type progressKey struct{}
+
+// WithProgress 返回携带进度回调的 context，批量和多页识别会通过该回调报告进度
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// withoutProgress 移除进度回调，用于嵌套处理 (如批量中的多页文档)，避免同一进度被重复报告
+func withoutProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, progressKey{}, ProgressFunc(nil))
+}
+
+// progressTracker 统计已完成项并报告进度 (并发安全)
+type progressTracker struct {
+	fn        ProgressFunc
+	total     int
+	done      int
+	startTime time.Time
+	mu        sync.Mutex
+}
+
+// newProgressTracker 创建进度跟踪器并报告初始进度，ctx 中没有进度回调时不报告
+func newProgressTracker(ctx context.Context, total int) *progressTracker {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+
+	tracker := &progressTracker{
+		fn:        fn,
+		total:     total,
+		startTime: time.Now(),
+	}
+
+	if fn != nil {
+		fn(0, total, fmt.Sprintf("0/%d started", total))
+	}
+
+	return tracker
+}
+
+// step 标记一项完成并报告进度 (item 为当前完成项，如文件路径或页码)
+func (t *progressTracker) step(item string) {
+	if t.fn == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done++
+	t.fn(t.done, t.total, fmt.Sprintf("%d/%d %s (elapsed %.1fs)",
+		t.done, t.total, item, time.Since(t.startTime).Seconds()))
+}
\ No newline at end of file
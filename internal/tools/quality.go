@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/preprocessing"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleAnalyzeImageQuality 评估图像是否适合 OCR (不执行识别)
+// 多页文档逐页评估，全部页面适合时 ready 为 true
+func (h *Handler) handleAnalyzeImageQuality(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	// 读取图像 (文件路径或 Base64)
+	imageData, err := h.readImageInput(args)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	info, err := h.inspectInput(imageData)
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	value, err := h.runTask(ctx, "quality", func(ctx context.Context) (interface{}, error) {
+		pages, err := h.splitPages(ctx, imageData, info.Format)
+		if err != nil {
+			return nil, err
+		}
+
+		if pages == nil {
+			report, err := h.analyzeQuality(imageData)
+			if err != nil {
+				return nil, err
+			}
+			return qualityReportMap(report), nil
+		}
+
+		ready := true
+		pageResults := make([]map[string]interface{}, 0, len(pages))
+		for i, page := range pages {
+			report, err := h.analyzeQuality(page)
+			if err != nil {
+				return nil, err
+			}
+
+			entry := qualityReportMap(report)
+			entry["page"] = i + 1
+			pageResults = append(pageResults, entry)
+			ready = ready && report.Ready
+		}
+
+		return map[string]interface{}{
+			"ready":      ready,
+			"pages":      pageResults,
+			"page_count": len(pages),
+		}, nil
+	})
+	if err != nil {
+		return h.errorResult(err), nil
+	}
+
+	result := value.(map[string]interface{})
+	result["input_format"] = string(info.Format)
+
+	return h.successResult(result), nil
+}
+
+// analyzeQuality 检查单页图像并评估 OCR 适用性
+func (h *Handler) analyzeQuality(imageData []byte) (*preprocessing.QualityReport, error) {
+	if _, err := h.inspectImage(imageData); err != nil {
+		return nil, err
+	}
+
+	report, err := h.preprocessor.AnalyzeQuality(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Image quality analyzed",
+		zap.Bool("ready", report.Ready),
+		zap.Strings("issues", report.Issues),
+		zap.Float64("dpi", report.DPI),
+	)
+
+	return report, nil
+}
+
+// qualityReportMap 将评估结果转换为响应
+func qualityReportMap(report *preprocessing.QualityReport) map[string]interface{} {
+	result := map[string]interface{}{
+		"ready":              report.Ready,
+		"issues":             report.Issues,
+		"warnings":           report.Warnings,
+		"width":              report.Width,
+		"height":             report.Height,
+		"sharpness":          report.Quality.Sharpness,
+		"contrast":           report.Quality.Contrast,
+		"brightness":         report.Quality.Brightness,
+		"skew_angle":         report.SkewAngle,
+		"text_height":        report.TextHeight,
+		"text_density":       report.TextDensity,
+		"suggested_pipeline": report.Quality.SuggestedPipeline,
+	}
+
+	if report.DPISource != "" {
+		result["dpi"] = report.DPI
+		result["dpi_source"] = report.DPISource
+	}
+
+	return result
+}
\ No newline at end of file
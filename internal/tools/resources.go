@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/input"
+	"github.com/ricardo/mcp-ocr-server/internal/results"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+	"github.com/ricardo/mcp-ocr-server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 资源 URI
+const (
+	resultURIPrefix = "ocr://results/"
+	imageURIPrefix  = "ocr://images/"
+	processedSuffix = "/processed"
+)
+
+// storedResult 保存的识别结果及其输入 (用于重新生成预处理图像)
+type storedResult struct {
+	tool   string
+	owner  string // 生成结果的调用方 (未认证时为空)
+	result []byte // JSON 编码的识别结果
+	input  []byte
+	params recognizeParams
+}
+
+// callerKey context 中调用方的键
+type callerKey struct{}
+
+// caller 已认证的调用方
+type caller struct {
+	name   string
+	allows func(tool string) bool
+}
+
+// WithCaller 返回携带已认证调用方的 context
+// 保存的识别结果只对生成它的调用方可见，且只能读取 allows 允许的工具生成的结果；
+// context 中没有调用方 (stdio 或未启用认证) 时不限制
+func WithCaller(ctx context.Context, name string, allows func(tool string) bool) context.Context {
+	return context.WithValue(ctx, callerKey{}, &caller{name: name, allows: allows})
+}
+
+// callerFromContext 获取 context 中的调用方
+func callerFromContext(ctx context.Context) *caller {
+	c, _ := ctx.Value(callerKey{}).(*caller)
+	return c
+}
+
+// ownerFromContext 调用方名称，作为保存结果和异步任务的所有者 (未认证时为空)
+func ownerFromContext(ctx context.Context) string {
+	if c := callerFromContext(ctx); c != nil {
+		return c.name
+	}
+	return ""
+}
+
+// canAccess 调用方是否可以访问保存的结果
+func (c *caller) canAccess(stored *storedResult) bool {
+	if c == nil {
+		return true
+	}
+	return stored.owner == c.name && (c.allows == nil || c.allows(stored.tool))
+}
+
+// GetResourceTemplates 获取 MCP 资源模板
+func GetResourceTemplates() []mcp.ResourceTemplate {
+	return []mcp.ResourceTemplate{
+		{
+			URITemplate: resultURIPrefix + "{id}",
+			Name:        "OCR result",
+			Description: "A previous OCR result (same JSON as the tool response); the id is returned as a resource link by recognition tools",
+			MIMEType:    "application/json",
+		},
+		{
+			URITemplate: imageURIPrefix + "{id}" + processedSuffix,
+			Name:        "Preprocessed image",
+			Description: "The preprocessed image fed to the OCR engine for a previous result (one PNG per page)",
+			MIMEType:    "image/png",
+		},
+	}
+}
+
+// resultURI 识别结果资源 URI
+func resultURI(id string) string {
+	return resultURIPrefix + id
+}
+
+// processedImageURI 预处理图像资源 URI
+func processedImageURI(id string) string {
+	return imageURIPrefix + id + processedSuffix
+}
+
+// storeResult 保存识别结果并返回包含资源链接的成功结果 (未启用结果存储时不保存)
+// 结果归属 ctx 中的调用方
+func (h *Handler) storeResult(ctx context.Context, tool string, result interface{}, imageData []byte, params recognizeParams) *mcp.CallToolResult {
+	callResult := h.successResult(result)
+	if h.resultStore == nil {
+		return callResult
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.Warn("Failed to encode result for storage", zap.Error(err))
+		return callResult
+	}
+
+	id, err := h.resultStore.Put(&storedResult{
+		tool:   tool,
+		owner:  ownerFromContext(ctx),
+		result: data,
+		input:  imageData,
+		params: params,
+	}, int64(len(data)+len(imageData)))
+	if errors.Is(err, results.ErrTooLarge) {
+		logger.Debug("Result too large to store, no resource link returned",
+			zap.String("tool", tool), zap.Int("size", len(data)+len(imageData)))
+		return callResult
+	}
+	if err != nil {
+		logger.Warn("Failed to store result", zap.Error(err))
+		return callResult
+	}
+
+	callResult.Content = append(callResult.Content,
+		mcp.ResourceLink{
+			Type:     "resource_link",
+			URI:      resultURI(id),
+			Name:     "result-" + id,
+			MIMEType: "application/json",
+		},
+		mcp.ResourceLink{
+			Type:     "resource_link",
+			URI:      processedImageURI(id),
+			Name:     "processed-" + id,
+			MIMEType: "image/png",
+		},
+	)
+
+	return callResult
+}
+
+// ListResources 列出 ctx 中的调用方可以访问的识别结果和预处理图像 (最新的在前)
+func (h *Handler) ListResources(ctx context.Context) []mcp.Resource {
+	if h.resultStore == nil {
+		return []mcp.Resource{}
+	}
+
+	c := callerFromContext(ctx)
+	entries := h.resultStore.List()
+	resources := make([]mcp.Resource, 0, len(entries)*2)
+	for _, entry := range entries {
+		stored := entry.Value.(*storedResult)
+		if !c.canAccess(stored) {
+			continue
+		}
+
+		created := entry.CreatedAt.Format("2006-01-02 15:04:05")
+
+		resources = append(resources,
+			mcp.Resource{
+				URI:         resultURI(entry.ID),
+				Name:        "result-" + entry.ID,
+				Description: fmt.Sprintf("%s result (%s)", stored.tool, created),
+				MIMEType:    "application/json",
+			},
+			mcp.Resource{
+				URI:         processedImageURI(entry.ID),
+				Name:        "processed-" + entry.ID,
+				Description: fmt.Sprintf("Preprocessed image for %s result (%s)", stored.tool, created),
+				MIMEType:    "image/png",
+			},
+		)
+	}
+
+	return resources
+}
+
+// ReadResource 读取识别结果或预处理图像资源
+func (h *Handler) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	if id, ok := strings.CutPrefix(uri, resultURIPrefix); ok {
+		stored, err := h.getStoredResult(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []mcp.ResourceContents{
+				{URI: uri, MIMEType: "application/json", Text: string(stored.result)},
+			},
+		}, nil
+	}
+
+	if rest, ok := strings.CutPrefix(uri, imageURIPrefix); ok {
+		if id, ok := strings.CutSuffix(rest, processedSuffix); ok {
+			stored, err := h.getStoredResult(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+
+			return h.readProcessedImages(ctx, uri, stored)
+		}
+	}
+
+	return nil, ocrErrors.New(ocrErrors.ErrResourceNotFound, fmt.Sprintf("unknown resource: %s", uri))
+}
+
+// getStoredResult 获取 ctx 中的调用方可以访问的识别结果 (无权访问时同样返回不存在)
+func (h *Handler) getStoredResult(ctx context.Context, id string) (*storedResult, error) {
+	if h.resultStore == nil {
+		return nil, ocrErrors.New(ocrErrors.ErrResourceNotFound, "result storage is disabled")
+	}
+
+	entry, err := h.resultStore.Get(id)
+	if errors.Is(err, results.ErrNotFound) {
+		return nil, ocrErrors.New(ocrErrors.ErrResourceNotFound, "result not found or expired").
+			WithDetails("id", id)
+	}
+	if err != nil {
+		return nil, ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "failed to read result")
+	}
+
+	stored := entry.Value.(*storedResult)
+	if !callerFromContext(ctx).canAccess(stored) {
+		return nil, ocrErrors.New(ocrErrors.ErrResourceNotFound, "result not found or expired").
+			WithDetails("id", id)
+	}
+
+	return stored, nil
+}
+
+// readProcessedImages 对保存的输入重新执行预处理 (不重新识别)，多页文档每页返回一个图像
+func (h *Handler) readProcessedImages(ctx context.Context, uri string, stored *storedResult) (*mcp.ReadResourceResult, error) {
+	value, err := h.runTask(ctx, uri, func(ctx context.Context) (interface{}, error) {
+		format := input.DetectFormat(stored.input)
+		pages, err := h.splitPages(ctx, stored.input, format)
+		if err != nil {
+			return nil, err
+		}
+		if pages == nil {
+			pages = [][]byte{stored.input}
+		}
+
+		contents := make([]mcp.ResourceContents, 0, len(pages))
+		for _, page := range pages {
+			// 与识别时一致: 预处理失败时使用原始图像
+			processed, err := h.preprocess(ctx, page, stored.params)
+			if err != nil {
+				return nil, err
+			}
+
+			contents = append(contents, mcp.ResourceContents{
+				URI:      uri,
+				MIMEType: imageMIMEType(input.DetectFormat(processed)),
+				Blob:     processed,
+			})
+		}
+		return contents, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{Contents: value.([]mcp.ResourceContents)}, nil
+}
+
+// imageMIMEType 图像格式对应的 MIME 类型
+func imageMIMEType(format input.Format) string {
+	switch format {
+	case "":
+		return "application/octet-stream"
+	case input.FormatPDF:
+		return "application/pdf"
+	default:
+		return "image/" + string(format)
+	}
+}
\ No newline at end of file
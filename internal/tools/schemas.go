@@ -2,6 +2,7 @@ package tools
 
 import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ricardo/mcp-ocr-server/internal/preprocessing"
 )
 
 // GetToolSchemas 获取所有 MCP Tool Schema
@@ -15,7 +16,7 @@ func GetToolSchemas() []mcp.Tool {
 				Properties: map[string]interface{}{
 					"image_path": map[string]interface{}{
 						"type":        "string",
-						"description": "Path to the image file to process",
+						"description": "Path to the image or PDF file to process (pages of PDF and multi-page TIFF files are recognized one by one)",
 					},
 					"language": map[string]interface{}{
 						"type":        "string",
@@ -27,11 +28,32 @@ func GetToolSchemas() []mcp.Tool {
 						"description": "Enable image preprocessing for better OCR results",
 						"default":     true,
 					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
 					"auto_mode": map[string]interface{}{
 						"type":        "boolean",
 						"description": "Enable automatic quality analysis and adaptive preprocessing",
 						"default":     true,
 					},
+					"debug": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also return preprocessing debug info per page: applied steps, image quality metrics (sharpness, contrast, brightness) and per-step timings. Bypasses cached results",
+						"default":     false,
+					},
+					"debug_images": map[string]interface{}{
+						"type":        "boolean",
+						"description": "With debug, also return the intermediate image after each preprocessing step as PNG image content",
+						"default":     false,
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: plain text, hOCR (XHTML), ALTO XML v4 or Tesseract TSV",
+						"enum":        []string{"text", "hocr", "alto", "tsv"},
+						"default":     "text",
+					},
 				},
 				Required: []string{"image_path"},
 			},
@@ -44,7 +66,7 @@ func GetToolSchemas() []mcp.Tool {
 				Properties: map[string]interface{}{
 					"image_base64": map[string]interface{}{
 						"type":        "string",
-						"description": "Base64-encoded image data",
+						"description": "Base64-encoded image or PDF data",
 					},
 					"language": map[string]interface{}{
 						"type":        "string",
@@ -56,15 +78,86 @@ func GetToolSchemas() []mcp.Tool {
 						"description": "Enable image preprocessing",
 						"default":     true,
 					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
 					"auto_mode": map[string]interface{}{
 						"type":        "boolean",
 						"description": "Enable automatic quality analysis",
 						"default":     true,
 					},
+					"debug": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also return preprocessing debug info per page: applied steps, image quality metrics (sharpness, contrast, brightness) and per-step timings. Bypasses cached results",
+						"default":     false,
+					},
+					"debug_images": map[string]interface{}{
+						"type":        "boolean",
+						"description": "With debug, also return the intermediate image after each preprocessing step as PNG image content",
+						"default":     false,
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: plain text, hOCR (XHTML), ALTO XML v4 or Tesseract TSV",
+						"enum":        []string{"text", "hocr", "alto", "tsv"},
+						"default":     "text",
+					},
 				},
 				Required: []string{"image_base64"},
 			},
 		},
+		{
+			Name:        "ocr_recognize_url",
+			Description: "Download an image or PDF over HTTP(S) and recognize its text (size, content type and host restrictions apply)",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_url": map[string]interface{}{
+						"type":        "string",
+						"description": "HTTP or HTTPS URL of the image or PDF",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "Language for OCR recognition",
+						"default":     "eng",
+					},
+					"preprocess": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable image preprocessing",
+						"default":     true,
+					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
+					"auto_mode": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable automatic quality analysis",
+						"default":     true,
+					},
+					"debug": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also return preprocessing debug info per page: applied steps, image quality metrics (sharpness, contrast, brightness) and per-step timings. Bypasses cached results",
+						"default":     false,
+					},
+					"debug_images": map[string]interface{}{
+						"type":        "boolean",
+						"description": "With debug, also return the intermediate image after each preprocessing step as PNG image content",
+						"default":     false,
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: plain text, hOCR (XHTML), ALTO XML v4 or Tesseract TSV",
+						"enum":        []string{"text", "hocr", "alto", "tsv"},
+						"default":     "text",
+					},
+				},
+				Required: []string{"image_url"},
+			},
+		},
 		{
 			Name:        "ocr_batch_recognize",
 			Description: "Recognize text from multiple images in batch",
@@ -73,7 +166,7 @@ func GetToolSchemas() []mcp.Tool {
 				Properties: map[string]interface{}{
 					"image_paths": map[string]interface{}{
 						"type":        "array",
-						"description": "Array of image file paths to process",
+						"description": "Array of image or PDF file paths to process",
 						"items": map[string]interface{}{
 							"type": "string",
 						},
@@ -88,15 +181,245 @@ func GetToolSchemas() []mcp.Tool {
 						"description": "Enable image preprocessing",
 						"default":     true,
 					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
 					"auto_mode": map[string]interface{}{
 						"type":        "boolean",
 						"description": "Enable automatic quality analysis",
 						"default":     true,
 					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: plain text, hOCR (XHTML), ALTO XML v4 or Tesseract TSV",
+						"enum":        []string{"text", "hocr", "alto", "tsv"},
+						"default":     "text",
+					},
 				},
 				Required: []string{"image_paths"},
 			},
 		},
+		{
+			Name:        "ocr_recognize_with_layout",
+			Description: "Recognize text and return words, lines, paragraphs or blocks with bounding boxes and per-item confidence. Coordinates refer to the image passed to the engine (the preprocessed image when preprocess is enabled)",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the image file to process (either image_path or image_base64 is required)",
+					},
+					"image_base64": map[string]interface{}{
+						"type":        "string",
+						"description": "Base64-encoded image data (either image_path or image_base64 is required)",
+					},
+					"level": map[string]interface{}{
+						"type":        "string",
+						"description": "Layout level of the returned items",
+						"enum":        []string{"block", "paragraph", "line", "word", "symbol"},
+						"default":     "word",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "Language for OCR recognition",
+						"default":     "eng",
+					},
+					"preprocess": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable image preprocessing",
+						"default":     true,
+					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
+					"auto_mode": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable automatic quality analysis",
+						"default":     true,
+					},
+					"debug": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also return preprocessing debug info per page: applied steps, image quality metrics (sharpness, contrast, brightness) and per-step timings. Bypasses cached results",
+						"default":     false,
+					},
+					"debug_images": map[string]interface{}{
+						"type":        "boolean",
+						"description": "With debug, also return the intermediate image after each preprocessing step as PNG image content",
+						"default":     false,
+					},
+				},
+			},
+		},
+		{
+			Name:        "ocr_create_searchable_pdf",
+			Description: "Create a searchable PDF from one or more images: each page shows the original image with an invisible OCR text layer aligned to word boxes",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_paths": map[string]interface{}{
+						"type":        "array",
+						"description": "Image file paths, one PDF page per image in the given order (PDF and multi-page TIFF inputs add one page per page)",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+					"output_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the PDF file to write",
+					},
+					"dpi": map[string]interface{}{
+						"type":        "number",
+						"description": "Image resolution used to compute the physical page size",
+						"default":     300,
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "Language for OCR recognition",
+						"default":     "eng",
+					},
+					"preprocess": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable image preprocessing before OCR (the PDF always embeds the original image)",
+						"default":     true,
+					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
+					"auto_mode": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable automatic quality analysis",
+						"default":     true,
+					},
+				},
+				Required: []string{"image_paths", "output_path"},
+			},
+		},
+		{
+			Name:        "ocr_submit_job",
+			Description: "Submit a batch of images as an asynchronous job and return immediately with a job ID; poll with ocr_job_status and fetch results with ocr_job_results",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_paths": map[string]interface{}{
+						"type":        "array",
+						"description": "Array of image or PDF file paths to process",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "Language for OCR recognition",
+						"default":     "eng",
+					},
+					"preprocess": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable image preprocessing",
+						"default":     true,
+					},
+					"pipeline": pipelineSchema(),
+					"profile": map[string]interface{}{
+						"type":        "string",
+						"description": "Named preprocessing profile from the server config (see ocr_list_profiles); provides the pipeline and OCR settings unless pipeline or language are given explicitly",
+					},
+					"auto_mode": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Enable automatic quality analysis",
+						"default":     true,
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: plain text, hOCR (XHTML), ALTO XML v4 or Tesseract TSV",
+						"enum":        []string{"text", "hocr", "alto", "tsv"},
+						"default":     "text",
+					},
+				},
+				Required: []string{"image_paths"},
+			},
+		},
+		{
+			Name:        "ocr_job_status",
+			Description: "Get the status and per-item progress of an asynchronous OCR job",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Job ID returned by ocr_submit_job",
+					},
+				},
+				Required: []string{"job_id"},
+			},
+		},
+		{
+			Name:        "ocr_job_results",
+			Description: "Get the results of an asynchronous OCR job; while the job is running, completed items are returned as partial results",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Job ID returned by ocr_submit_job",
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Index of the first item to return",
+						"default":     0,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of items to return (0 returns all)",
+						"default":     0,
+					},
+				},
+				Required: []string{"job_id"},
+			},
+		},
+		{
+			Name:        "ocr_cancel_job",
+			Description: "Cancel an asynchronous OCR job: queued items are skipped and running items are interrupted",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Job ID returned by ocr_submit_job",
+					},
+				},
+				Required: []string{"job_id"},
+			},
+		},
+		{
+			Name:        "ocr_list_profiles",
+			Description: "List the named preprocessing profiles (pipeline, language, page segmentation mode and character whitelist) configured on the server, plus the available preprocessing step names",
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "ocr_analyze_image_quality",
+			Description: "Check whether an image is suitable for OCR without recognizing it: returns sharpness, contrast, brightness, skew angle, DPI (from metadata or estimated from text height), text density and a ready verdict with the issues that require a rescan",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the image or PDF file to analyze (either image_path or image_base64 is required)",
+					},
+					"image_base64": map[string]interface{}{
+						"type":        "string",
+						"description": "Base64-encoded image data (either image_path or image_base64 is required)",
+					},
+				},
+			},
+		},
 		{
 			Name:        "ocr_get_supported_languages",
 			Description: "Get list of supported OCR languages",
@@ -106,4 +429,74 @@ func GetToolSchemas() []mcp.Tool {
 			},
 		},
 	}
+}
+
+// GetAdminToolSchemas 获取管理工具 Schema (security.admin_tools 启用时提供)
+func GetAdminToolSchemas() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "ocr_cache_stats",
+			Description: "Get result cache statistics: backend, entry count, size in bytes, limits, hits, misses, evictions, hit rate and in-flight recognitions",
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "ocr_cache_invalidate",
+			Description: "Remove all cached results of one image (every language, preprocessing and layout level), e.g. to drop bad results after updating tessdata",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"image_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the image or PDF file whose results to remove; page results of PDF and multi-page TIFF files are removed as well (either image_path or image_hash is required)",
+					},
+					"image_hash": map[string]interface{}{
+						"type":        "string",
+						"description": "Hex-encoded SHA256 of the image file contents, as printed by sha256sum (either image_path or image_hash is required)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "ocr_cache_clear",
+			Description: "Remove all cached OCR results",
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+	}
+}
+
+// IsAdminTool 是否为管理工具
+func IsAdminTool(name string) bool {
+	for _, tool := range GetAdminToolSchemas() {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pipelineSchema pipeline 参数的 Schema (各识别工具共用)
+func pipelineSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "array",
+		"description": "Explicit ordered preprocessing steps overriding auto/default selection, e.g. " +
+			`[{"step":"denoise","strength":7},{"step":"binarization","mode":"adaptive","block_size":31}]. ` +
+			"Parameters: denoise(strength), binarization(mode, block_size, c), deskew(angle_limit), " +
+			"contrast_enhance(clip_limit, tile_size), brighten/darken(amount), resize(width, height)",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"step": map[string]interface{}{
+					"type": "string",
+					"enum": preprocessing.StepNames(),
+				},
+			},
+			"required": []string{"step"},
+		},
+	}
 }
\ No newline at end of file
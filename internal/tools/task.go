@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ricardo/mcp-ocr-server/internal/pool"
+	ocrErrors "github.com/ricardo/mcp-ocr-server/pkg/errors"
+)
+
+// recognizeTask 在 Worker 池中执行的识别任务
+type recognizeTask struct {
+	id string
+	fn func(ctx context.Context) (interface{}, error)
+}
+
+// Execute 实现 pool.Task 接口
+func (t *recognizeTask) Execute(ctx context.Context) (interface{}, error) {
+	return t.fn(ctx)
+}
+
+// ID 实现 pool.Task 接口
+func (t *recognizeTask) ID() string {
+	return t.id
+}
+
+// runTask 在 Worker 池中执行单个任务并等待结果
+// 队列已满时立即返回 QUEUE_FULL 错误，由客户端决定是否重试
+func (h *Handler) runTask(ctx context.Context, id string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	future, err := h.workerPool.TrySubmit(ctx, &recognizeTask{id: id, fn: fn})
+	if err != nil {
+		return nil, h.taskError(err)
+	}
+
+	return h.waitTask(ctx, future)
+}
+
+// submitTask 提交任务到 Worker 池，队列已满时阻塞等待 (背压)，用于批量处理
+func (h *Handler) submitTask(ctx context.Context, id string, fn func(ctx context.Context) (interface{}, error)) (*pool.Future, error) {
+	future, err := h.workerPool.SubmitWait(ctx, &recognizeTask{id: id, fn: fn})
+	if err != nil {
+		return nil, h.taskError(err)
+	}
+	return future, nil
+}
+
+// waitTask 等待任务完成并返回结果
+func (h *Handler) waitTask(ctx context.Context, future *pool.Future) (interface{}, error) {
+	result, err := future.Wait(ctx)
+	if err != nil {
+		return nil, h.taskError(err)
+	}
+	if result.Error != nil {
+		return nil, h.taskError(result.Error)
+	}
+	return result.Value, nil
+}
+
+// withOCRTimeout 为单个图像的预处理和识别设置超时时间 (ocr.timeout)
+func (h *Handler) withOCRTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, time.Duration(h.config.OCR.Timeout)*time.Second)
+}
+
+// taskError 将 Worker 池和上下文错误转换为 OCR 错误
+func (h *Handler) taskError(err error) error {
+	var ocrErr *ocrErrors.OCRError
+	switch {
+	case errors.As(err, &ocrErr):
+		return err
+	case errors.Is(err, pool.ErrQueueFull):
+		return ocrErrors.Wrap(err, ocrErrors.ErrQueueFull, "server is busy, retry later").
+			WithDetails("queue_size", h.config.Performance.QueueSize).
+			WithDetails("workers", h.config.Performance.WorkerPoolSize)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ocrErrors.Wrap(err, ocrErrors.ErrTimeout, "request cancelled or timed out")
+	case errors.Is(err, pool.ErrPoolStopped):
+		return ocrErrors.Wrap(err, ocrErrors.ErrInternalError, "worker pool is not running")
+	default:
+		return err
+	}
+}
\ No newline at end of file
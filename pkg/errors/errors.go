@@ -13,6 +13,13 @@ const (
 	ErrPreprocessingFailed ErrorCode = "PREPROCESSING_FAILED"
 	ErrOCREngineFailed     ErrorCode = "OCR_ENGINE_FAILED"
 	ErrTimeout             ErrorCode = "TIMEOUT"
+	ErrQueueFull           ErrorCode = "QUEUE_FULL"
+	ErrJobNotFound         ErrorCode = "JOB_NOT_FOUND"
+	ErrResourceNotFound    ErrorCode = "RESOURCE_NOT_FOUND"
+	ErrUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrPathNotAllowed      ErrorCode = "PATH_NOT_ALLOWED"
+	ErrURLNotAllowed       ErrorCode = "URL_NOT_ALLOWED"
+	ErrFetchFailed         ErrorCode = "FETCH_FAILED"
 	ErrInternalError       ErrorCode = "INTERNAL_ERROR"
 )
 
@@ -61,4 +68,15 @@ func Wrap(err error, code ErrorCode, message string) *OCRError {
 func (e *OCRError) WithDetails(key string, value interface{}) *OCRError {
 	e.Details[key] = value
 	return e
+}
+
+// Clone 复制错误 (详细信息使用独立的 map)
+// 错误可能被多个调用方共享 (如合并的并发请求)，添加详细信息前应先复制
+func (e *OCRError) Clone() *OCRError {
+	clone := *e
+	clone.Details = make(map[string]interface{}, len(e.Details))
+	for key, value := range e.Details {
+		clone.Details[key] = value
+	}
+	return &clone
 }
\ No newline at end of file
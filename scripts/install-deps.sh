@@ -29,6 +29,10 @@ install_macos() {
     echo "Installing OpenCV..."
     brew install opencv
 
+    # 安装 Poppler (PDF 渲染)
+    echo "Installing Poppler..."
+    brew install poppler
+
     # 设置环境变量
     echo "Setting up environment variables..."
     export PKG_CONFIG_PATH="/usr/local/opt/opencv/lib/pkgconfig:$PKG_CONFIG_PATH"
@@ -60,6 +64,10 @@ install_ubuntu() {
         libopencv-dev \
         pkg-config
 
+    # 安装 Poppler (PDF 渲染)
+    echo "Installing Poppler..."
+    sudo apt-get install -y poppler-utils
+
     echo "✓ Ubuntu/Debian dependencies installed"
 }
 
@@ -80,6 +88,10 @@ install_centos() {
     echo "Installing OpenCV..."
     sudo yum install -y opencv-devel
 
+    # 安装 Poppler (PDF 渲染)
+    echo "Installing Poppler..."
+    sudo yum install -y poppler-utils
+
     echo "✓ CentOS/RHEL dependencies installed"
 }
 